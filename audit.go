@@ -0,0 +1,291 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// Kinds of manual change kept in the audit log.
+const (
+	auditRename     = "rename"
+	auditAdjustment = "adjustment"
+	auditStat       = "stat"
+	auditRole       = "role"
+	auditDelete     = "delete"
+	auditReimport   = "reimport"
+)
+
+// auditAtImport is the reason kept for corrections entered at the import prompts.
+const auditAtImport = "entered at import"
+
+type auditEntry struct {
+	AuditID    int    `json:"auditid"`
+	Kind       string `json:"kind"`
+	MatchID    int    `json:"matchid"`
+	Player     string `json:"player"`
+	Section    string `json:"section,omitempty"`
+	StatID     int    `json:"statid,omitempty"`
+	Field      string `json:"field"`
+	OldValue   string `json:"oldValue"`
+	NewValue   string `json:"newValue"`
+	Reason     string `json:"reason"`
+	ChangedBy  string `json:"changedBy"`
+	ChangedAt  string `json:"changedAt"`
+	RevertedBy int    `json:"revertedBy,omitempty"`
+}
+
+// queryRower is a database or a transaction.
+type queryRower interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// dbExecer is a database or a transaction, so a change and its audit
+// entry can be written together.
+type dbExecer interface {
+	queryRower
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// recordAudit keeps a manual change with who made it and when, and returns
+// its audit ID.
+func recordAudit(db dbExecer, e auditEntry) (int64, error) {
+	insertAuditSQL := `INSERT INTO audit_log (kind,matchid,player,section,statid,field,oldValue,newValue,reason,changedBy,changedAt)
+		VALUES (?,?,TRIM(?),?,?,?,?,?,?,?,?)`
+	res, err := db.Exec(insertAuditSQL, e.Kind, e.MatchID, e.Player, e.Section, e.StatID, e.Field, e.OldValue, e.NewValue,
+		strings.TrimSpace(e.Reason), currentUser(), time.Now().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func queryAuditLog(db *sql.DB, where string, args ...interface{}) ([]auditEntry, error) {
+	auditSQL := `select auditid, kind, coalesce(matchid, 0), coalesce(player, ''), coalesce(section, ''), coalesce(statid, 0),
+		coalesce(field, ''), coalesce(oldValue, ''), coalesce(newValue, ''), coalesce(reason, ''), coalesce(changedBy, ''),
+		coalesce(changedAt, ''), coalesce(revertedBy, 0)
+		from audit_log where ` + where + ` order by auditid`
+	entries := make([]auditEntry, 0)
+	err := queryRows(db, auditSQL, args, func(row *sql.Rows) error {
+		e := auditEntry{}
+		err := row.Scan(&e.AuditID, &e.Kind, &e.MatchID, &e.Player, &e.Section, &e.StatID, &e.Field, &e.OldValue, &e.NewValue,
+			&e.Reason, &e.ChangedBy, &e.ChangedAt, &e.RevertedBy)
+		entries = append(entries, e)
+		return err
+	})
+	return entries, err
+}
+
+// getAuditLog lists the changes to a match, or every change for matchid 0.
+func getAuditLog(db *sql.DB, matchid int) ([]auditEntry, error) {
+	if matchid == 0 {
+		return queryAuditLog(db, "1 = 1")
+	}
+	return queryAuditLog(db, "matchid = ?", matchid)
+}
+
+// getPlayerAuditLog lists the changes to a player's figures in a match,
+// including the replacement that gave the player their name.
+func getPlayerAuditLog(db *sql.DB, matchid int, playerName string) ([]auditEntry, error) {
+	return queryAuditLog(db, `matchid = ? AND (TRIM(player) = TRIM(?) OR (kind = ? AND TRIM(newValue) = TRIM(?)))`,
+		matchid, playerName, auditRename, playerName)
+}
+
+// latestAdjustment is the value last entered for a player's adjustment, "0" when none was.
+func latestAdjustment(db queryRower, matchid int, playerName string, field string) (string, error) {
+	var value string
+	adjSQL := `select value from adjustments where matchid = ? AND TRIM(player) = TRIM(?) AND field = ? order by rowid DESC limit 1`
+	err := db.QueryRow(adjSQL, matchid, playerName, field).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "0", nil
+	}
+	return value, err
+}
+
+// manualRole is a player's manually set role, "auto" when the role is inferred.
+func manualRole(db queryRower, playerName string) (string, error) {
+	var role string
+	err := db.QueryRow(`select role from player_roles where TRIM(player) = TRIM(?)`, playerName).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "auto", nil
+	}
+	return role, err
+}
+
+// revertAudit undoes one change, provided nothing has changed the same value
+// since, and keeps the revert in the log as a change of its own.
+func revertAudit(db *sql.DB, auditid int, reason string) (auditEntry, error) {
+	entries, err := queryAuditLog(db, "auditid = ?", auditid)
+	if err != nil {
+		return auditEntry{}, err
+	}
+	if len(entries) == 0 {
+		return auditEntry{}, notFoundError("there is no audit entry " + strconv.Itoa(auditid))
+	}
+	e := entries[0]
+	if e.RevertedBy != 0 {
+		return e, invalidError("audit entry " + strconv.Itoa(auditid) + " was already reverted by entry " + strconv.Itoa(e.RevertedBy))
+	}
+	if reason = strings.TrimSpace(reason); reason != "" {
+		reason = "revert of " + strconv.Itoa(auditid) + " : " + reason
+	} else {
+		reason = "revert of " + strconv.Itoa(auditid)
+	}
+	changedSince := invalidError(e.Field + " has changed since audit entry " + strconv.Itoa(auditid) + ", revert the later change first")
+
+	// the change and its revertedBy link are written together
+	tx, err := db.Begin()
+	if err != nil {
+		return e, err
+	}
+	defer tx.Rollback()
+
+	var revertid int64
+	switch e.Kind {
+	case auditRename:
+		there, err := checkPlayerThere(tx, e.MatchID, e.NewValue)
+		if err != nil {
+			return e, err
+		}
+		if !there {
+			return e, invalidError(e.NewValue + " is no longer in Match ID " + strconv.Itoa(e.MatchID))
+		}
+		if revertid, err = replacePlayerName(tx, e.MatchID, e.NewValue, e.OldValue, reason); err != nil {
+			return e, err
+		}
+	case auditAdjustment:
+		latest, err := latestAdjustment(tx, e.MatchID, e.Player, e.Field)
+		if err != nil {
+			return e, err
+		}
+		if latest != e.NewValue {
+			return e, changedSince
+		}
+		old, _ := strconv.Atoi(e.OldValue)
+		if revertid, err = recordAdjustment(tx, e.MatchID, e.Player, e.Field, old, reason); err != nil {
+			return e, err
+		}
+	case auditStat:
+		t := statTables[e.Section]
+		var current sql.NullString
+		err := tx.QueryRow(`select `+e.Field+` from `+t.table+` where `+t.id+` = ?`, e.StatID).Scan(&current)
+		if err == sql.ErrNoRows {
+			return e, invalidError("the " + e.Section + " row of audit entry " + strconv.Itoa(auditid) + " no longer exists")
+		} else if err != nil {
+			return e, err
+		}
+		if current.String != e.NewValue {
+			return e, changedSince
+		}
+		edit := statEdit{MatchID: e.MatchID, Section: e.Section, RowID: e.StatID, Player: e.Player, Field: e.Field, OldValue: e.NewValue, NewValue: e.OldValue}
+		if revertid, err = changeStat(tx, t, edit, reason); err != nil {
+			return e, err
+		}
+	case auditRole:
+		current, err := manualRole(tx, e.Player)
+		if err != nil {
+			return e, err
+		}
+		if current != e.NewValue {
+			return e, changedSince
+		}
+		old := e.OldValue
+		if old == "" {
+			old = "auto"
+		}
+		if revertid, err = storePlayerRole(tx, e.Player, old, reason); err != nil {
+			return e, err
+		}
+	default:
+		return e, invalidError("a " + e.Kind + " cannot be reverted, re-import the match's scorecard instead")
+	}
+
+	if _, err := tx.Exec(`UPDATE audit_log SET revertedBy = ? WHERE auditid = ?`, revertid, auditid); err != nil {
+		return e, err
+	}
+	if err := tx.Commit(); err != nil {
+		return e, err
+	}
+	log.Println("Audit entry " + strconv.Itoa(auditid) + " reverted")
+
+	// the points are recalculated from the committed change
+	switch e.Kind {
+	case auditAdjustment:
+		err = applyAdjustments(db)
+		if err == nil {
+			err = calculatePointsFinal(db)
+		}
+	case auditStat:
+		err = calculatePoints(db)
+	case auditRole:
+		err = applyRoleBonuses(db)
+		if err == nil {
+			err = calculatePointsFinal(db)
+		}
+	}
+	if err != nil {
+		return e, err
+	}
+	entries, err = queryAuditLog(db, "auditid = ?", auditid)
+	if err != nil {
+		return e, err
+	}
+	return entries[0], nil
+}
+
+// reapplyRenames replaces the names in a re-imported match the way they
+// were replaced before, skipping replacements that were reverted.
+func reapplyRenames(db *sql.DB, matchid int) error {
+	renames, err := queryAuditLog(db, "matchid = ? AND kind = ? AND revertedBy IS NULL", matchid, auditRename)
+	if err != nil {
+		return err
+	}
+	for _, e := range renames {
+		there, err := checkPlayerThere(db, matchid, e.OldValue)
+		if err != nil {
+			return err
+		}
+		if there {
+			if err = updatePlayerName(db, matchid, e.OldValue, e.NewValue); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// describeAudit is one line of a change for explain.
+func describeAudit(e auditEntry) string {
+	change := fmt.Sprintf("%d. %s %s : %s -> %s by %s on %s", e.AuditID, e.Kind, e.Field, e.OldValue, e.NewValue, e.ChangedBy, e.ChangedAt)
+	if e.Reason != "" {
+		change = change + " (" + e.Reason + ")"
+	}
+	if e.RevertedBy != 0 {
+		change = change + " reverted by " + strconv.Itoa(e.RevertedBy)
+	}
+	return change
+}
+
+func renderAuditLog(entries []auditEntry) {
+	t := table.NewWriter()
+	t.AppendHeader(table.Row{"Audit ID", "Changed At", "By", "Match ID", "Kind", "Player", "Field", "Old", "New", "Reason", "Reverted By"})
+	for _, e := range entries {
+		matchID, revertedBy := "", ""
+		if e.MatchID != 0 {
+			matchID = strconv.Itoa(e.MatchID)
+		}
+		if e.RevertedBy != 0 {
+			revertedBy = strconv.Itoa(e.RevertedBy)
+		}
+		t.AppendRow(table.Row{e.AuditID, e.ChangedAt, e.ChangedBy, matchID, e.Kind, e.Player, e.Field, e.OldValue, e.NewValue, e.Reason, revertedBy})
+	}
+	fmt.Println("------------------------------------------")
+	fmt.Println("Audit Log")
+	fmt.Println("------------------------------------------")
+	fmt.Println(t.Render())
+}
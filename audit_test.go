@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+)
+
+// A revert is linked to the audit entry it wrote, also for role changes,
+// which belong to no match, and for a revert among other changes of its kind.
+func TestRevertLinksItsOwnEntry(t *testing.T) {
+	db := openTestDB(t)
+	importTestScorecards(t, db, "scorecard.csv", "Mar3-20.csv")
+
+	for _, change := range []func() error{
+		func() error { return setPlayerRole(db, "Jai V", "bowler", "") },
+		func() error { return setPlayerRole(db, "Vikas Sawkar", "all-rounder", "") },
+		func() error { return setAdjustment(db, 1, "Jai V", "DropCatches", 1, "") },
+		func() error { return setAdjustment(db, 2, "Jai V", "DropCatches", 2, "") },
+	} {
+		if err := change(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := getAuditLog(db, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := len(entries)
+
+	// the first role and adjustment changes, each before a later one
+	for _, auditid := range []int{changes - 3, changes - 1} {
+		reverted, err := revertAudit(db, auditid, "")
+		if err != nil {
+			t.Fatalf("revert %d : %v", auditid, err)
+		}
+		revert, err := queryAuditLog(db, "auditid = ?", reverted.RevertedBy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "revert of " + strconv.Itoa(auditid)
+		if len(revert) != 1 || revert[0].Reason != want || revert[0].Player != reverted.Player || revert[0].MatchID != reverted.MatchID {
+			t.Errorf("entry %d reverted by %d %+v, want the entry for %q", auditid, reverted.RevertedBy, revert, want)
+		}
+	}
+	if role, err := manualRole(db, "Jai V"); err != nil || role != "auto" {
+		t.Errorf("Jai V role after the revert : %q %v, want auto", role, err)
+	}
+	if value, err := latestAdjustment(db, 1, "Jai V", "DropCatches"); err != nil || value != "0" {
+		t.Errorf("Jai V match 1 DropCatches after the revert : %q %v, want 0", value, err)
+	}
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// awardRule is one season award: the stat that decides it, the minimum
+// innings or overs to qualify and the stats that break a tie, in order.
+type awardRule struct {
+	Name              string   `json:"name"`
+	Stat              string   `json:"stat"`
+	MinBattingInnings int      `json:"minBattingInnings"`
+	MinBowlingInnings int      `json:"minBowlingInnings"`
+	MinOvers          int      `json:"minOvers"`
+	TieBreakers       []string `json:"tieBreakers"`
+}
+
+// seasonAwards are the awards given when there is no awards.json in the
+// working directory; awards.json holds a list of awardRule to use instead.
+var seasonAwards = []awardRule{
+	{Name: "Most Valuable Player", Stat: "points", TieBreakers: []string{"wickets", "runs"}},
+	{Name: "Best Batter", Stat: "runs", MinBattingInnings: 3, TieBreakers: []string{"battingAverage", "strikeRate"}},
+	{Name: "Best Bowler", Stat: "wickets", MinOvers: 6, TieBreakers: []string{"bowlingAverage", "economy"}},
+	{Name: "Best Fielder", Stat: "dismissals", TieBreakers: []string{"catches"}},
+	{Name: "Most Sixes", Stat: "sixes", TieBreakers: []string{"strikeRate"}},
+	{Name: "Best Economy", Stat: "economy", MinOvers: 10, TieBreakers: []string{"wickets"}},
+}
+
+const awardsFile = "awards.json"
+
+// awardStat reads one stat from a player's season, lower is better for
+// economy and bowling average.
+type awardStat struct {
+	value         func(s seasonStats, points int) float64
+	lowerIsBetter bool
+	decimals      int
+}
+
+var awardStats = map[string]awardStat{
+	"points":         {value: func(s seasonStats, points int) float64 { return float64(points) }},
+	"matches":        {value: func(s seasonStats, points int) float64 { return float64(s.Matches) }},
+	"runs":           {value: func(s seasonStats, points int) float64 { return float64(s.Batting.Runs) }},
+	"battingAverage": {value: func(s seasonStats, points int) float64 { return s.Batting.Average }, decimals: 2},
+	"strikeRate":     {value: func(s seasonStats, points int) float64 { return s.Batting.StrikeRate }, decimals: 2},
+	"fours":          {value: func(s seasonStats, points int) float64 { return float64(s.Batting.Fours) }},
+	"sixes":          {value: func(s seasonStats, points int) float64 { return float64(s.Batting.Sixers) }},
+	"wickets":        {value: func(s seasonStats, points int) float64 { return float64(s.Bowling.Wickets) }},
+	"maidens":        {value: func(s seasonStats, points int) float64 { return float64(s.Bowling.Maidens) }},
+	"economy":        {value: func(s seasonStats, points int) float64 { return s.Bowling.Economy }, lowerIsBetter: true, decimals: 2},
+	"bowlingAverage": {value: func(s seasonStats, points int) float64 { return s.Bowling.Average }, lowerIsBetter: true, decimals: 2},
+	"catches": {value: func(s seasonStats, points int) float64 {
+		return float64(s.Fielding.Catches + s.Fielding.KeeperDismissals)
+	}},
+	"dismissals": {value: func(s seasonStats, points int) float64 {
+		return float64(s.Fielding.Catches + s.Fielding.KeeperDismissals + s.Fielding.RunOuts)
+	}},
+}
+
+type awardNominee struct {
+	Player string `json:"player"`
+	Value  string `json:"value"`
+}
+
+type award struct {
+	Name     string         `json:"name"`
+	Stat     string         `json:"stat"`
+	Winners  []awardNominee `json:"winners"`
+	RunnerUp []awardNominee `json:"runnersUp"`
+}
+
+type seasonHonors struct {
+	Series string  `json:"series"`
+	Awards []award `json:"awards"`
+}
+
+// getAwardRules returns the awards from awards.json when there is one.
+func getAwardRules() ([]awardRule, error) {
+	if !fileExists(awardsFile) {
+		return seasonAwards, nil
+	}
+	config, err := os.ReadFile(awardsFile)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]awardRule, 0)
+	if err := json.Unmarshal(config, &rules); err != nil {
+		return nil, errors.New(awardsFile + " : " + err.Error())
+	}
+	for _, rule := range rules {
+		for _, stat := range append([]string{rule.Stat}, rule.TieBreakers...) {
+			if _, ok := awardStats[stat]; !ok {
+				return nil, errors.New(awardsFile + " : " + rule.Name + " uses unknown stat " + stat)
+			}
+		}
+	}
+	return rules, nil
+}
+
+// awardStatsSQL totals every player's batting, bowling and fielding for a
+// series, or for all matches when the series is empty. Innings are counted
+// like addBattingInnings and addBowlingInnings, and scorecard overs such as
+// "3.2" (3 overs and 2 balls) are turned into balls like oversToBalls.
+const awardStatsSQL = `select b.player, b.matches, b.innings, b.notouts, b.runs, b.balls, b.fours, b.sixers,
+		COALESCE(w.innings, 0), COALESCE(w.balls, 0), COALESCE(w.maidens, 0), COALESCE(w.runsGiven, 0), COALESCE(w.wickets, 0),
+		COALESCE(f.catches, 0), COALESCE(f.keeper, 0), COALESCE(f.runouts, 0)
+	from (select TRIM(b.battername) player, count(*) matches,
+			sum(CASE WHEN b.runs > 0 OR b.balls > 0 THEN 1 ELSE 0 END) innings,
+			sum(CASE WHEN (b.runs > 0 OR b.balls > 0) AND b.Notout = 1 THEN 1 ELSE 0 END) notouts,
+			sum(b.runs) runs, sum(b.balls) balls, sum(b.fours) fours, sum(b.sixers) sixers
+		from batsmen b JOIN "match" m ON b.matchid = m.matchid
+		where (?1 = '' OR m.series = ?1) group by TRIM(b.battername)) b
+	LEFT JOIN (select player, sum(CASE WHEN balls > 0 THEN 1 ELSE 0 END) innings, sum(balls) balls,
+			sum(CASE WHEN balls > 0 THEN Maidens ELSE 0 END) maidens,
+			sum(CASE WHEN balls > 0 THEN RunsGiven ELSE 0 END) runsGiven,
+			sum(CASE WHEN balls > 0 THEN wickets ELSE 0 END) wickets
+		from (select TRIM(w.bowlerName) player, w.Maidens, w.RunsGiven, w.wickets,
+				CAST(w.overs AS INTEGER) * 6 + CAST(ROUND((CAST(w.overs AS REAL) - CAST(w.overs AS INTEGER)) * 10) AS INTEGER) balls
+			from bowlers w JOIN "match" m ON w.matchid = m.matchid
+			where (?1 = '' OR m.series = ?1))
+		group by player) w ON w.player = b.player
+	LEFT JOIN (select TRIM(f.fieldername) player,
+			sum(CASE WHEN f.wicketType = 'CaughtBehind' THEN 0 ELSE f.catches END) catches,
+			sum(CASE WHEN f.wicketType = 'CaughtBehind' THEN f.catches ELSE 0 END) keeper,
+			sum(f.runouts) runouts
+		from fielders f JOIN "match" m ON f.matchid = m.matchid
+		where (?1 = '' OR m.series = ?1) group by TRIM(f.fieldername)) f ON f.player = b.player
+	order by b.player`
+
+// getSeasonTotals returns the statistics of every player who played in the
+// series, or in any match when series is empty, keyed by player.
+func getSeasonTotals(db *sql.DB, series string) (map[string]seasonStats, error) {
+	totals := make(map[string]seasonStats)
+	err := queryRows(db, awardStatsSQL, []interface{}{series}, func(row *sql.Rows) error {
+		var player string
+		stats := seasonStats{Series: series}
+		b, w, f := &stats.Batting, &stats.Bowling, &stats.Fielding
+		err := row.Scan(&player, &stats.Matches, &b.Innings, &b.NotOuts, &b.Runs, &b.Balls, &b.Fours, &b.Sixers,
+			&w.Innings, &w.Balls, &w.Maidens, &w.RunsGiven, &w.Wickets, &f.Catches, &f.KeeperDismissals, &f.RunOuts)
+		if err != nil {
+			return err
+		}
+		finishStats(&stats)
+		totals[player] = stats
+		return nil
+	})
+	return totals, err
+}
+
+// getAwards works out the awards for a series, or for all matches when
+// series is empty. Players tied on the stat and every tie-breaker share
+// the award.
+func getAwards(db *sql.DB, series string) (seasonHonors, error) {
+
+	honors := seasonHonors{Series: series}
+	if series == "" {
+		honors.Series = "All Matches"
+	}
+
+	type candidate struct {
+		player string
+		stats  seasonStats
+		points int
+	}
+	rules, err := getAwardRules()
+	if err != nil {
+		return honors, err
+	}
+	leaderboard, err := getLeaderboard(db, series)
+	if err != nil {
+		return honors, err
+	}
+	points := make(map[string]int)
+	for _, e := range leaderboard {
+		points[strings.TrimSpace(e.Player)] = e.Points
+	}
+	totals, err := getSeasonTotals(db, series)
+	if err != nil {
+		return honors, err
+	}
+	candidates := make([]candidate, 0)
+	for player, stats := range totals {
+		candidates = append(candidates, candidate{player: player, stats: stats, points: points[player]})
+	}
+
+	for _, rule := range rules {
+		qualified := make([]candidate, 0)
+		for _, c := range candidates {
+			if c.stats.Batting.Innings < rule.MinBattingInnings || c.stats.Bowling.Innings < rule.MinBowlingInnings || c.stats.Bowling.Balls/6 < rule.MinOvers {
+				continue
+			}
+			// nobody wins an award for none, e.g. no sixes or no overs bowled
+			if awardStats[rule.Stat].value(c.stats, c.points) == 0 {
+				continue
+			}
+			qualified = append(qualified, c)
+		}
+
+		order := append([]string{rule.Stat}, rule.TieBreakers...)
+		compare := func(a, b candidate) int {
+			for _, stat := range order {
+				s := awardStats[stat]
+				va, vb := s.value(a.stats, a.points), s.value(b.stats, b.points)
+				if s.lowerIsBetter {
+					va, vb = -va, -vb
+				}
+				if va > vb {
+					return -1
+				}
+				if va < vb {
+					return 1
+				}
+			}
+			return 0
+		}
+		sort.SliceStable(qualified, func(a, b int) bool {
+			if c := compare(qualified[a], qualified[b]); c != 0 {
+				return c < 0
+			}
+			return qualified[a].player < qualified[b].player
+		})
+
+		a := award{Name: rule.Name, Stat: rule.Stat}
+		for i, c := range qualified {
+			s := awardStats[rule.Stat]
+			nominee := awardNominee{Player: c.player, Value: fmt.Sprintf("%.*f", s.decimals, s.value(c.stats, c.points))}
+			if compare(c, qualified[0]) == 0 {
+				a.Winners = append(a.Winners, nominee)
+			} else if i < len(a.Winners)+2 {
+				a.RunnerUp = append(a.RunnerUp, nominee)
+			}
+		}
+		honors.Awards = append(honors.Awards, a)
+	}
+	return honors, nil
+}
+
+// renderAwardsMarkdown writes the awards as a script to read out at the
+// awards night.
+func renderAwardsMarkdown(honors seasonHonors) string {
+	var report strings.Builder
+	report.WriteString("# Phoenix Season Awards : " + honors.Series + "\n")
+	for _, a := range honors.Awards {
+		report.WriteString("\n## " + a.Name + "\n\n")
+		if len(a.Winners) == 0 {
+			report.WriteString("Not awarded, no player qualified.\n")
+			continue
+		}
+		names := make([]string, 0)
+		for _, w := range a.Winners {
+			names = append(names, "**"+w.Player+"**")
+		}
+		if len(a.Winners) > 1 {
+			report.WriteString("Shared by " + strings.Join(names, " and ") + " with " + a.Winners[0].Value + " " + a.Stat + " each.\n")
+		} else {
+			report.WriteString("And the award goes to " + names[0] + " with " + a.Winners[0].Value + " " + a.Stat + "!\n")
+		}
+		for _, r := range a.RunnerUp {
+			report.WriteString("\n- Runner up : " + r.Player + " (" + r.Value + ")")
+		}
+		if len(a.RunnerUp) > 0 {
+			report.WriteString("\n")
+		}
+	}
+	return report.String()
+}
+
+func writeAwardsReport(honors seasonHonors) {
+	awardsReport := "awards_" + strings.ReplaceAll(honors.Series, " ", "_") + ".md"
+	err := os.WriteFile(awardsReport, []byte(renderAwardsMarkdown(honors)), 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Println("Awards report : " + awardsReport + " created ")
+}
+
+func renderAwards(honors seasonHonors) {
+	t := table.NewWriter()
+	t.AppendHeader(table.Row{"Award", "Winner", "Value", "Runners Up"})
+	for _, a := range honors.Awards {
+		winners := make([]string, 0)
+		for _, w := range a.Winners {
+			winners = append(winners, w.Player)
+		}
+		value := "not awarded"
+		if len(a.Winners) > 0 {
+			value = a.Winners[0].Value + " " + a.Stat
+		}
+		runnersUp := make([]string, 0)
+		for _, r := range a.RunnerUp {
+			runnersUp = append(runnersUp, fmt.Sprintf("%s (%s)", r.Player, r.Value))
+		}
+		t.AppendRow(table.Row{a.Name, strings.Join(winners, ", "), value, strings.Join(runnersUp, ", ")})
+	}
+	fmt.Println("------------------------------------------")
+	fmt.Println("Season Awards : " + honors.Series)
+	fmt.Println("------------------------------------------")
+	fmt.Println(t.Render())
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"testing"
+)
+
+// The season totals from the one query must match what the player profile
+// works out innings by innings.
+func TestSeasonTotalsMatchProfiles(t *testing.T) {
+	db := openTestDB(t)
+	importTestScorecards(t, db, "scorecard.csv", "Mar3-20.csv")
+
+	totals, err := getSeasonTotals(db, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	players, err := getPlayers(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(totals) != len(players) {
+		t.Errorf("totals for %d players, want %d", len(totals), len(players))
+	}
+	for _, player := range players {
+		profile, err := getPlayerProfile(db, player)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, got := profile.Career, totals[player]
+		want.Series, got.Series = "", ""
+		// best figures are not needed for the awards
+		want.Batting.HighScore, want.Batting.HighNotOut, want.Batting.Ducks = 0, false, 0
+		want.Bowling.BestWickets, want.Bowling.BestRuns = 0, 0
+		got.Batting.Highest, got.Bowling.Best = want.Batting.Highest, want.Bowling.Best
+		if want != got {
+			t.Errorf("%s : totals %+v, want %+v", player, got, want)
+		}
+	}
+}
+
+// minOvers counts completed overs, 5.5 overs is short of 6.
+func TestAwardMinOvers(t *testing.T) {
+	db := openTestDB(t)
+	importTestScorecards(t, db, "scorecard.csv", "Mar3-20.csv")
+
+	bestBowler := func() string {
+		t.Helper()
+		honors, err := getAwards(db, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, a := range honors.Awards {
+			if a.Name == "Best Bowler" && len(a.Winners) > 0 {
+				return a.Winners[0].Player
+			}
+		}
+		return ""
+	}
+	// Vikas Sawkar bowls 4.0 and 2.0 overs for 4 wickets
+	if got := bestBowler(); got != "Vikas Sawkar" {
+		t.Errorf("best bowler with 6 overs : %q, want Vikas Sawkar", got)
+	}
+	if _, err := db.Exec(`UPDATE bowlers SET overs = '1.5' WHERE matchid = 2 AND TRIM(bowlerName) = 'Vikas Sawkar'`); err != nil {
+		t.Fatal(err)
+	}
+	if got := bestBowler(); got != "Thenappan Nachiappan" {
+		t.Errorf("best bowler with Vikas Sawkar on 5.5 overs : %q, want Thenappan Nachiappan", got)
+	}
+}
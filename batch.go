@@ -0,0 +1,385 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+const importErrorsFile = "import_errors.txt"
+
+// batchImportTxSize is how many scorecards a batch import stores per transaction.
+const batchImportTxSize = 25
+
+// Batch import outcomes for a scorecard file.
+const (
+	batchImported = "imported"
+	batchSkipped  = "skipped"
+	batchFailed   = "failed"
+)
+
+type batchFile struct {
+	Path      string `json:"path"`
+	MatchDate string `json:"matchDate"`
+	Status    string `json:"status"`
+	MatchID   int    `json:"matchid"`
+	Reason    string `json:"reason"`
+	played    time.Time
+}
+
+// findScorecards expands each argument, a directory (its .csv files), a
+// glob pattern or a file, into the scorecard files to import.
+func findScorecards(patterns []string) ([]string, error) {
+	seen := make(map[string]bool)
+	files := make([]string, 0)
+	for _, pattern := range patterns {
+		if info, err := os.Stat(pattern); err == nil && info.IsDir() {
+			pattern = filepath.Join(pattern, "*.csv")
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, errors.New(pattern + " : " + err.Error())
+		}
+		for _, m := range matches {
+			if filepath.Ext(m) != ".csv" || isGeneratedFile(m) || seen[m] {
+				continue
+			}
+			seen[m] = true
+			files = append(files, m)
+		}
+	}
+	if len(files) == 0 {
+		return nil, errors.New("no scorecard csv files found in " + strings.Join(patterns, " "))
+	}
+	return files, nil
+}
+
+// isGeneratedFile reports whether a .csv file is this program's own output,
+// points_<matchid>.csv or leaderboard.csv, rather than a scorecard.
+func isGeneratedFile(path string) bool {
+	name := filepath.Base(path)
+	if name == leaderboardFile {
+		return true
+	}
+	matchid, isPoints := strings.CutPrefix(name, "points_")
+	matchid, isCSV := strings.CutSuffix(matchid, ".csv")
+	_, err := strconv.Atoi(matchid)
+	return isPoints && isCSV && err == nil
+}
+
+// batchImport imports the scorecards in match date order into the store
+// without any prompts. A file whose match is already in the store is
+// skipped, a file that is not a valid scorecard fails and the rest carry on.
+//
+// Files are validated and parsed by a pool of workers, one per CPU, while a
+// single writer stores them in date order, batchImportTxSize files to a
+// transaction. Points are calculated once at the end.
+func batchImport(store Store, files []string) ([]batchFile, error) {
+
+	batch := make([]batchFile, len(files))
+	runWorkers(len(files), func(i int) { batch[i] = readBatchFile(files[i]) })
+	sort.SliceStable(batch, func(a, b int) bool { return batch[a].played.Before(batch[b].played) })
+
+	type parseResult struct {
+		index  int
+		parsed parsedScorecard
+		err    error
+	}
+	results := make(chan parseResult, batchImportWorkers())
+	go func() {
+		runWorkers(len(batch), func(i int) {
+			if batch[i].Status != batchFailed {
+				parsed, err := parseScorecard(batch[i].Path)
+				results <- parseResult{index: i, parsed: parsed, err: err}
+			}
+		})
+		close(results)
+	}()
+
+	// The writer takes the parsed files back in date order, so match IDs
+	// follow the match dates. A match seen earlier in the batch may not be
+	// saved yet, so it is found by date and teams here instead of the store.
+	pending := make(map[int]parseResult)
+	next := 0
+	firstFile := make(map[string]int)
+	duplicates := make(map[int]int)
+	saving := make([]int, 0)
+	chunk := make([]parsedScorecard, 0)
+	save := func() error {
+		if len(chunk) == 0 {
+			return nil
+		}
+		ids, err := store.SaveScorecards(chunk)
+		if err != nil {
+			return err
+		}
+		for j, id := range ids {
+			batch[saving[j]].MatchID, batch[saving[j]].Status = id, batchImported
+		}
+		saving, chunk = saving[:0], chunk[:0]
+		return nil
+	}
+	writeReady := func() error {
+		for ; next < len(batch); next++ {
+			if batch[next].Status == batchFailed {
+				continue
+			}
+			r, ok := pending[next]
+			if !ok {
+				return nil
+			}
+			delete(pending, next)
+			b := &batch[next]
+			if r.err != nil {
+				b.Status, b.Reason = batchFailed, r.err.Error()
+				continue
+			}
+			parsed := r.parsed
+			m := parsed.Match
+			key := m.MatchDate + "|" + m.Team1 + "|" + m.Team2
+			if first, ok := firstFile[key]; ok {
+				b.Status, duplicates[next] = batchSkipped, first
+				continue
+			}
+			id, err := store.FindMatch(m)
+			if err != nil {
+				return err
+			}
+			if id != 0 {
+				b.Status, b.MatchID, b.Reason = batchSkipped, id, "already imported as Match ID "+fmt.Sprint(id)
+				continue
+			}
+			firstFile[key] = next
+			saving, chunk = append(saving, next), append(chunk, parsed)
+			if len(chunk) >= batchImportTxSize {
+				if err := save(); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	var err error
+	for r := range results {
+		pending[r.index] = r
+		if err == nil {
+			err = writeReady()
+		}
+	}
+	if err == nil {
+		err = writeReady()
+	}
+	if err == nil {
+		err = save()
+	}
+	if err != nil {
+		return batch, err
+	}
+	for i, first := range duplicates {
+		batch[i].MatchID, batch[i].Reason = batch[first].MatchID, "already imported as Match ID "+fmt.Sprint(batch[first].MatchID)
+	}
+
+	if err = store.CalculatePoints(); err != nil {
+		return batch, err
+	}
+	if outputs, ok := store.(importOutputWriter); ok {
+		for _, b := range batch {
+			if b.Status == batchImported {
+				if err = outputs.writeImportOutputs(b.Path, b.MatchID); err != nil {
+					return batch, err
+				}
+			}
+		}
+	}
+	return batch, nil
+}
+
+// runWorkers calls work for 0..n-1 on batchImportWorkers goroutines and
+// waits for them all.
+func runWorkers(n int, work func(i int)) {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < batchImportWorkers(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				work(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+func batchImportWorkers() int {
+	return runtime.NumCPU()
+}
+
+// scorecardWriter stores parsed scorecards in one transaction with the
+// insert statements prepared once.
+type scorecardWriter struct {
+	tx                                        *sql.Tx
+	match, batsmen, bowlers, fielders, inning *sql.Stmt
+}
+
+// newScorecardWriter begins the transaction, the caller commits it or
+// rolls it back.
+func newScorecardWriter(db *sql.DB) (*scorecardWriter, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	w := &scorecardWriter{tx: tx}
+	statements := []struct {
+		statement **sql.Stmt
+		query     string
+	}{
+		{&w.match, `INSERT INTO match (series,stage,division,matchDate,Team1,Team2,Result) VALUES (?, ?, ?,?, ?, ?, ? )`},
+		{&w.batsmen, `INSERT INTO batsmen (matchid,battername,runs,balls,fours,sixers,Notout) VALUES (?,?,?,?,?,?,?)`},
+		{&w.bowlers, `INSERT INTO bowlers (matchid,bowlerName,overs,Maidens,RunsGiven,Wickets,Wides,NoBalls) VALUES (?, ?, ?,?, ?, ? ,?,?)`},
+		{&w.fielders, `INSERT INTO fielders (matchid,Batsman,wicketType,fieldername,bowlername,bowled,catches,runouts) VALUES (?,?,?,?,?,?,?,?)`},
+		{&w.inning, `INSERT INTO innings (matchid,team,runs,wickets,overs) VALUES (?,?,?,?,?)`},
+	}
+	for _, s := range statements {
+		if *s.statement, err = tx.Prepare(s.query); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// write stores one scorecard and returns its new match ID.
+func (w *scorecardWriter) write(parsed parsedScorecard) (int, error) {
+	m := parsed.Match
+	res, err := w.match.Exec(m.Series, m.Stage, m.Division, m.MatchDate, m.Team1, m.Team2, m.Result)
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	if err = w.writeStats(int(id), parsed); err != nil {
+		return 0, err
+	}
+	log.Println("Match Saved as Match ID := " + fmt.Sprint(id))
+	return int(id), nil
+}
+
+// writeStats stores a scorecard's batting, bowling, fielding and innings
+// totals under match ID id.
+func (w *scorecardWriter) writeStats(id int, parsed parsedScorecard) error {
+	for _, b := range parsed.Batting {
+		if _, err := w.batsmen.Exec(id, b.Name, b.Runs, b.Balls, b.Fours, b.Sixers, b.NotOut); err != nil {
+			return err
+		}
+	}
+	for _, b := range parsed.Bowling {
+		if _, err := w.bowlers.Exec(id, b.Name, b.Overs, b.Maidens, b.RunsGiven, b.Wickets, b.Wides, b.NoBalls); err != nil {
+			return err
+		}
+	}
+	for _, f := range parsed.Fielding {
+		if _, err := w.fielders.Exec(id, f.Batsman, f.WicketType, f.Fielder, f.Bowler, f.Bowled, f.Catches, f.RunOuts); err != nil {
+			return err
+		}
+	}
+	for _, total := range parsed.Innings {
+		if _, err := w.inning.Exec(id, total.Team, total.Runs, total.Wickets, total.Overs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *scorecardWriter) commit() error {
+	return w.tx.Commit()
+}
+
+// readBatchFile validates a scorecard and reads its match date.
+func readBatchFile(path string) batchFile {
+	b := batchFile{Path: path}
+	if err := validateScorecard(path); err != nil {
+		b.Status, b.Reason = batchFailed, err.Error()
+		return b
+	}
+	m, err := readMatchHeader(path)
+	if err != nil {
+		b.Status, b.Reason = batchFailed, err.Error()
+		return b
+	}
+	b.MatchDate = m.MatchDate
+	played, err := time.Parse("01/02/2006", b.MatchDate)
+	if err != nil {
+		b.Status, b.Reason = batchFailed, "match date "+b.MatchDate+" is not MM/DD/YYYY"
+		return b
+	}
+	b.played = played
+	return b
+}
+
+// findImportedMatch returns the ID of an imported match with the same
+// date and teams, 0 when there is none. Given a transaction, matches saved
+// earlier in it count.
+func findImportedMatch(db queryRower, m matchDetails) (int, error) {
+	var id int
+	err := db.QueryRow(`select matchid from "match" where matchDate = ? AND Team1 = ? AND Team2 = ?`, m.MatchDate, m.Team1, m.Team2).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return id, err
+}
+
+// writeImportErrors writes every failed file with its reason to
+// import_errors.txt, removing an old report when nothing failed.
+func writeImportErrors(batch []batchFile) {
+	var report strings.Builder
+	for _, b := range batch {
+		if b.Status == batchFailed {
+			report.WriteString(b.Path + " : " + b.Reason + "\n")
+		}
+	}
+	if report.Len() == 0 {
+		os.Remove(importErrorsFile)
+		return
+	}
+	err := os.WriteFile(importErrorsFile, []byte(report.String()), 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Println("Import errors : " + importErrorsFile + " created ")
+}
+
+func renderBatchImport(batch []batchFile) {
+	counts := make(map[string]int)
+	t := table.NewWriter()
+	t.AppendHeader(table.Row{"S.No", "File", "Match Date", "Status", "Match ID", "Reason"})
+	for i, b := range batch {
+		counts[b.Status] = counts[b.Status] + 1
+		id := ""
+		if b.MatchID != 0 {
+			id = fmt.Sprint(b.MatchID)
+		}
+		t.AppendRow(table.Row{i + 1, b.Path, b.MatchDate, b.Status, id, b.Reason})
+	}
+	fmt.Println("------------------------------------------")
+	fmt.Printf("Batch Import : %d imported, %d skipped, %d failed\n", counts[batchImported], counts[batchSkipped], counts[batchFailed])
+	fmt.Println("------------------------------------------")
+	fmt.Println(t.Render())
+}
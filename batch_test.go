@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// The program's own csv outputs next to the scorecards are neither imported
+// nor quarantined, while a scorecard that only looks like one is imported.
+func TestGeneratedFilesSkipped(t *testing.T) {
+	dir := t.TempDir()
+	scorecard, err := os.ReadFile("scorecard.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour)
+	for _, name := range []string{"scorecard.csv", "points_1.csv", "points_12.csv", leaderboardFile, "points_table.csv"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, scorecard, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := findScorecards([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(dir, "points_table.csv"), filepath.Join(dir, "scorecard.csv")}
+	if len(files) != len(want) || files[0] != want[0] || files[1] != want[1] {
+		t.Errorf("findScorecards %v, want %v", files, want)
+	}
+
+	// the two scorecards are the same match, one is imported and the other
+	// skipped, neither fails
+	db := openTestDB(t)
+	// the leaderboard is written to the working directory
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	watchOnce(db, dir)
+	if _, err := os.Stat(filepath.Join(dir, quarantineDir)); !os.IsNotExist(err) {
+		t.Errorf("watch quarantined files : %v", err)
+	}
+	for _, name := range []string{"points_1.csv", "points_12.csv", leaderboardFile} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s : %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,357 @@
+package main
+
+import (
+	"bufio"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// commandUsage lists the commands available besides importing a scorecard.
+var commandUsage = map[string]string{
+	"explain":    "explain <matchid> <player name>",
+	"summary":    "summary <matchid> [markdown|text]",
+	"serve":      "serve [address, default :8080]",
+	"profile":    "profile <player name>",
+	"standings":  "standings [series, e.g. \"Spring 2022\"]",
+	"headtohead": "headtohead [opponent]",
+	"dreamteam":  "dreamteam <matchid> | dreamteam <from MM/DD/YYYY> <to MM/DD/YYYY>",
+	"import":     "import <directory, glob or scorecard files...>",
+	"watch":      "watch <directory> [poll seconds, default 30]",
+	"migrate":    "migrate [status]",
+	"delete":     "delete <matchid>",
+	"reimport":   "reimport <matchid> <corrected scorecard.csv>",
+	"edit":       "edit <matchid> <batting|bowling|fielding> <field> <value> <player name, or fielding row ID>",
+	"audit":      "audit [matchid] | audit revert <audit ID>",
+	"records":    "records [matchid, the records it broke]",
+	"awards":     "awards [series]",
+	"form":       "form [last N matches, default 5] [json]",
+	"roles":      "roles | roles <batter|bowler|all-rounder|wicket-keeper|auto> <player name>",
+	"fantasy":    "fantasy enter <matchid or MM/DD/YYYY> | fantasy prices <matchid or MM/DD/YYYY> | fantasy entries <matchid or MM/DD/YYYY> | fantasy leaderboard [series]",
+}
+
+func isCommand(name string) bool {
+	_, ok := commandUsage[name]
+	return ok
+}
+
+func runCommand(args []string) {
+	if storeCommands[args[0]] {
+		runStoreCommand(openStore(), args)
+		return
+	}
+	if os.Getenv(databaseURLEnv) != "" {
+		log.Println(args[0] + " works on phoenixPoints.db only, unset " + databaseURLEnv + " to use it.")
+		os.Exit(1)
+	}
+	dbconn := Dbconnect()
+	if args[0] == "migrate" {
+		if len(args) > 1 && args[1] != "status" {
+			commandUsageExit(args[0])
+		}
+		if len(args) == 1 {
+			migrateDatabase(dbconn)
+		}
+		renderMigrations(dbconn)
+		return
+	}
+	CreateTables(dbconn)
+
+	switch args[0] {
+	case "explain":
+		if len(args) < 3 {
+			commandUsageExit(args[0])
+		}
+		explainPoints(dbconn, commandMatchID(args[0], args[1]), strings.Join(args[2:], " "))
+	case "summary":
+		if len(args) < 2 {
+			commandUsageExit(args[0])
+		}
+		format := "markdown"
+		if len(args) > 2 {
+			format = args[2]
+		}
+		summary, err := renderMatchSummary(dbconn, commandMatchID(args[0], args[1]), format)
+		exitOnError(err)
+		fmt.Print(summary)
+	case "profile":
+		if len(args) < 2 {
+			commandUsageExit(args[0])
+		}
+		profile, err := getPlayerProfile(dbconn, strings.Join(args[1:], " "))
+		exitOnError(err)
+		renderPlayerProfile(profile)
+	case "standings":
+		standings, err := getStandings(dbconn, strings.Join(args[1:], " "))
+		exitOnError(err)
+		renderStandings(standings)
+	case "headtohead":
+		records, err := getHeadToHead(dbconn, strings.Join(args[1:], " "))
+		exitOnError(err)
+		renderHeadToHead(records)
+	case "dreamteam":
+		var matchids []int
+		if len(args) == 2 {
+			matchids = []int{commandMatchID(args[0], args[1])}
+		} else if len(args) == 3 {
+			var err error
+			matchids, err = matchesBetween(dbconn, args[1], args[2])
+			if err != nil {
+				log.Println(err.Error())
+				commandUsageExit(args[0])
+			}
+		} else {
+			commandUsageExit(args[0])
+		}
+		team, err := getDreamTeam(dbconn, matchids)
+		exitOnError(err)
+		renderDreamTeam(team)
+	case "watch":
+		if len(args) < 2 {
+			commandUsageExit(args[0])
+		}
+		if info, err := os.Stat(args[1]); err != nil || !info.IsDir() {
+			log.Println(args[1] + " is not a directory.")
+			commandUsageExit(args[0])
+		}
+		poll := watchDefaultPoll
+		if len(args) > 2 {
+			seconds, err := strconv.Atoi(args[2])
+			if err != nil || seconds < 1 {
+				log.Println(args[2] + " is not a number of seconds.")
+				commandUsageExit(args[0])
+			}
+			poll = time.Duration(seconds) * time.Second
+		}
+		watchFolder(dbconn, args[1], poll)
+	case "delete":
+		if len(args) != 2 {
+			commandUsageExit(args[0])
+		}
+		id := commandMatchID(args[0], args[1])
+		m, err := getMatchDetails(dbconn, id)
+		exitOnError(err)
+		fmt.Println("Delete Match ID " + args[1] + " : " + m.MatchDate + " " + m.Team1 + " Vs " + m.Team2 + " with its stats, adjustments and points ? (Yes Or No) (Default:No) ")
+		fmt.Print("==> ")
+		reader := bufio.NewReader(os.Stdin)
+		text, _ := reader.ReadString('\n')
+		if answer := strings.ToLower(strings.TrimSpace(text)); answer != "yes" && answer != "y" {
+			return
+		}
+		if err := deleteMatch(dbconn, id, promptReason(reader)); err != nil {
+			log.Println(err.Error())
+			os.Exit(1)
+		}
+	case "reimport":
+		if len(args) != 3 {
+			commandUsageExit(args[0])
+		}
+		id := commandMatchID(args[0], args[1])
+		if err := reimportMatch(dbconn, id, args[2], promptReason(bufio.NewReader(os.Stdin))); err != nil {
+			log.Println(err.Error())
+			os.Exit(1)
+		}
+		renderFinalTable(dbconn)
+		renderPointsBreakdown(dbconn)
+	case "edit":
+		if len(args) < 6 {
+			commandUsageExit(args[0])
+		}
+		id := commandMatchID(args[0], args[1])
+		e, err := editMatchStat(dbconn, id, strings.ToLower(args[2]), args[3], args[4], strings.Join(args[5:], " "), promptReason(bufio.NewReader(os.Stdin)))
+		if err != nil {
+			log.Println(err.Error())
+			os.Exit(1)
+		}
+		renderStatEdit(e)
+		matchid = id
+		renderPointsBreakdown(dbconn)
+	case "audit":
+		if len(args) > 1 && args[1] == "revert" {
+			if len(args) != 3 {
+				commandUsageExit(args[0])
+			}
+			auditid, err := strconv.Atoi(args[2])
+			if err != nil {
+				log.Println(args[2] + " is not an audit ID.")
+				commandUsageExit(args[0])
+			}
+			e, err := revertAudit(dbconn, auditid, promptReason(bufio.NewReader(os.Stdin)))
+			if err != nil {
+				log.Println(err.Error())
+				os.Exit(1)
+			}
+			entries, err := getAuditLog(dbconn, e.MatchID)
+			exitOnError(err)
+			renderAuditLog(entries)
+		} else {
+			id := 0
+			if len(args) > 1 {
+				id = commandMatchID(args[0], args[1])
+			}
+			entries, err := getAuditLog(dbconn, id)
+			exitOnError(err)
+			renderAuditLog(entries)
+		}
+	case "records":
+		var records []clubRecord
+		var err error
+		if len(args) > 1 {
+			records, err = getBrokenRecords(dbconn, commandMatchID(args[0], args[1]))
+		} else {
+			records, err = getRecords(dbconn)
+		}
+		exitOnError(err)
+		renderRecords(records)
+	case "awards":
+		honors, err := getAwards(dbconn, strings.Join(args[1:], " "))
+		exitOnError(err)
+		renderAwards(honors)
+		writeAwardsReport(honors)
+	case "form":
+		n := formDefaultMatches
+		asJSON := false
+		for _, arg := range args[1:] {
+			if arg == "json" {
+				asJSON = true
+				continue
+			}
+			n = commandCount(args[0], arg)
+		}
+		form, err := getForm(dbconn, n)
+		exitOnError(err)
+		if asJSON {
+			renderFormJSON(form)
+		} else {
+			renderForm(form, n)
+		}
+	case "roles":
+		if len(args) == 2 {
+			commandUsageExit(args[0])
+		}
+		if len(args) > 2 {
+			err := setPlayerRole(dbconn, strings.Join(args[2:], " "), args[1], promptReason(bufio.NewReader(os.Stdin)))
+			if errors.Is(err, errInvalid) {
+				log.Println(err.Error())
+				commandUsageExit(args[0])
+			}
+			exitOnError(err)
+		}
+		roles, err := getPlayerRoles(dbconn)
+		exitOnError(err)
+		renderPlayerRoles(sortedPlayerRoles(roles))
+	case "fantasy":
+		if len(args) < 2 {
+			commandUsageExit(args[0])
+		}
+		switch {
+		case args[1] == "enter" && len(args) == 3:
+			id, matchDate := fantasyMatch(dbconn, args[2])
+			processFantasyEntry(dbconn, id, matchDate)
+		case args[1] == "prices" && len(args) == 3:
+			_, matchDate := fantasyMatch(dbconn, args[2])
+			prices, err := getFantasyPrices(dbconn, matchDate)
+			exitOnError(err)
+			renderFantasyPrices(matchDate, prices)
+		case args[1] == "entries" && len(args) == 3:
+			id, matchDate := fantasyMatch(dbconn, args[2])
+			entries, err := getFantasyEntries(dbconn, id, matchDate)
+			exitOnError(err)
+			renderFantasyEntries(entries)
+		case args[1] == "leaderboard":
+			leaderboard, err := getFantasyLeaderboard(dbconn, strings.Join(args[2:], " "))
+			exitOnError(err)
+			renderFantasyLeaderboard(leaderboard)
+		default:
+			commandUsageExit(args[0])
+		}
+	}
+}
+
+// runStoreCommand runs the commands that work on either store.
+func runStoreCommand(store Store, args []string) {
+	switch args[0] {
+	case "import":
+		if len(args) < 2 {
+			commandUsageExit(args[0])
+		}
+		files, err := findScorecards(args[1:])
+		if err != nil {
+			log.Println(err.Error())
+			os.Exit(1)
+		}
+		batch, err := batchImport(store, files)
+		exitOnError(err)
+		renderBatchImport(batch)
+		writeImportErrors(batch)
+		for _, b := range batch {
+			if b.Status == batchFailed {
+				os.Exit(1)
+			}
+		}
+	case "serve":
+		addr := ":8080"
+		if len(args) > 1 {
+			addr = args[1]
+		}
+		serve(store, addr)
+	}
+}
+
+// promptReason asks why a manual change is made, for the audit log.
+func promptReason(reader *bufio.Reader) string {
+	fmt.Println("Reason for the change ? (Default:none) ")
+	fmt.Print("==> ")
+	text, _ := reader.ReadString('\n')
+	return strings.TrimSpace(text)
+}
+
+func commandMatchID(command string, arg string) int {
+	id, err := strconv.Atoi(arg)
+	if err != nil {
+		log.Println(arg + " is not a Match ID.")
+		commandUsageExit(command)
+	}
+	return id
+}
+
+func commandCount(command string, arg string) int {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 {
+		log.Println(arg + " is not a number of matches.")
+		commandUsageExit(command)
+	}
+	return n
+}
+
+// fantasyMatch accepts either an imported match's ID or a match date, with
+// match ID 0, as entries can be made before the match is imported.
+func fantasyMatch(db *sql.DB, arg string) (int, string) {
+	if id, err := strconv.Atoi(arg); err == nil {
+		m, err := getMatchDetails(db, id)
+		if errors.Is(err, errNotFound) {
+			log.Println("Match ID " + arg + " is not found.")
+			commandUsageExit("fantasy")
+		}
+		exitOnError(err)
+		return m.MatchID, m.MatchDate
+	}
+	return 0, arg
+}
+
+// exitOnError ends a command that could not read or change the database.
+func exitOnError(err error) {
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+}
+
+func commandUsageExit(command string) {
+	log.Println("Usage : " + os.Args[0] + " " + commandUsage[command])
+	os.Exit(1)
+}
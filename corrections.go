@@ -0,0 +1,281 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// statTable is a stats table the edit command can change: its row ID, the
+// column naming the player and the columns that may be edited. A batter's
+// or bowler's name is not one of them, renaming a player changes every
+// table and goes through replacePlayerName.
+type statTable struct {
+	table  string
+	id     string
+	player string
+	fields []string
+	text   []string
+}
+
+var statTables = map[string]statTable{
+	"batting": {table: "batsmen", id: "batterid", player: "battername",
+		fields: []string{"runs", "balls", "fours", "sixers", "Notout"}},
+	"bowling": {table: "bowlers", id: "bowlerid", player: "bowlerName",
+		fields: []string{"overs", "Maidens", "RunsGiven", "wickets", "Wides", "NoBalls"}, text: []string{"overs"}},
+	"fielding": {table: "fielders", id: "fieldingid", player: "Batsman",
+		fields: []string{"Batsman", "wicketType", "fieldername", "bowlername", "bowled", "catches", "runouts"}, text: []string{"Batsman", "wicketType", "fieldername", "bowlername"}},
+}
+
+// wicketTypes are the dismissals parseFielding stores.
+var wicketTypes = []string{"Caught", "Caught&Bowled", "Bowled", "RunOut", "RunOut-DirectHit", "CaughtBehind"}
+
+type statEdit struct {
+	MatchID  int    `json:"matchid"`
+	Section  string `json:"section"`
+	RowID    int    `json:"rowid"`
+	Player   string `json:"player"`
+	Field    string `json:"field"`
+	OldValue string `json:"oldValue"`
+	NewValue string `json:"newValue"`
+}
+
+func matchExists(db *sql.DB, matchid int) (bool, error) {
+	var count int
+	err := db.QueryRow(`select count(*) from "match" where matchid = ?`, matchid).Scan(&count)
+	return count > 0, err
+}
+
+// deleteMatch removes a match with its batting, bowling, fielding, innings
+// and adjustments (the foreign keys cascade), its points and output files,
+// and recalculates the remaining points.
+func deleteMatch(db *sql.DB, matchid int, reason string) error {
+	m, err := getMatchDetails(db, matchid)
+	if err != nil {
+		return err
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	if hasTable(db, "TotalMatchPoints") {
+		if _, err = tx.Exec(`DELETE FROM TotalMatchPoints WHERE matchid = ?`, matchid); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if _, err = tx.Exec(`DELETE FROM "match" WHERE matchid = ?`, matchid); err != nil {
+		tx.Rollback()
+		return err
+	}
+	_, err = recordAudit(tx, auditEntry{Kind: auditDelete, MatchID: matchid, Field: "match",
+		OldValue: m.MatchDate + " " + m.Team1 + " Vs " + m.Team2, Reason: reason})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	log.Println("Match ID " + strconv.Itoa(matchid) + " deleted")
+
+	for _, f := range []string{"points_%d.csv", "report_%d.html", "summary_%d.md"} {
+		os.Remove(fmt.Sprintf(f, matchid))
+	}
+	return calculatePoints(db)
+}
+
+// reimportMatch replaces a match's details and stats with those of a
+// corrected scorecard, keeping its match ID, manual adjustments and the
+// name replacements in the audit log.
+func reimportMatch(db *sql.DB, id int, scorecard string, reason string) error {
+	exists, err := matchExists(db, id)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return notFoundError("there is no Match ID " + strconv.Itoa(id))
+	}
+	if err := validateScorecard(scorecard); err != nil {
+		return invalidError(scorecard + " is not a valid scorecard : " + err.Error())
+	}
+	parsed, err := parseScorecard(scorecard)
+	if err != nil {
+		return invalidError(scorecard + " is not a valid scorecard : " + err.Error())
+	}
+
+	writer, err := newScorecardWriter(db)
+	if err != nil {
+		return err
+	}
+	other, err := findImportedMatch(writer.tx, parsed.Match)
+	if err != nil {
+		writer.tx.Rollback()
+		return err
+	}
+	if other != 0 && other != id {
+		writer.tx.Rollback()
+		return invalidError(scorecard + " is Match ID " + strconv.Itoa(other) + ", not " + strconv.Itoa(id))
+	}
+	m := parsed.Match
+	statements := []struct {
+		query string
+		args  []interface{}
+	}{
+		{`UPDATE "match" SET series=?, stage=?, division=?, matchDate=?, Team1=?, Team2=?, Result=? WHERE matchid = ?`,
+			[]interface{}{m.Series, m.Stage, m.Division, m.MatchDate, m.Team1, m.Team2, m.Result, id}},
+		{`DELETE FROM batsmen WHERE matchid = ?`, []interface{}{id}},
+		{`DELETE FROM bowlers WHERE matchid = ?`, []interface{}{id}},
+		{`DELETE FROM fielders WHERE matchid = ?`, []interface{}{id}},
+		{`DELETE FROM innings WHERE matchid = ?`, []interface{}{id}},
+	}
+	for _, s := range statements {
+		if _, err := writer.tx.Exec(s.query, s.args...); err != nil {
+			writer.tx.Rollback()
+			return err
+		}
+	}
+	if err = writer.writeStats(id, parsed); err != nil {
+		writer.tx.Rollback()
+		return err
+	}
+	if err = writer.commit(); err != nil {
+		return err
+	}
+	log.Println("Match ID " + strconv.Itoa(id) + " re-imported from " + scorecard)
+	_, err = recordAudit(db, auditEntry{Kind: auditReimport, MatchID: id, Field: "scorecard", NewValue: scorecard, Reason: reason})
+	if err != nil {
+		return err
+	}
+
+	if err = reapplyRenames(db, id); err != nil {
+		return err
+	}
+	if err = calculatePoints(db); err != nil {
+		return err
+	}
+	matchid, currentMatch = id, id
+	if err = writeMatchOutputs(scorecard, db); err != nil {
+		return err
+	}
+	return announceRecords(db, id)
+}
+
+// editMatchStat sets one field of a player's batting or bowling row, or of
+// a fielding row chosen by dismissed batsman or row ID, and recalculates
+// the points.
+func editMatchStat(db *sql.DB, matchid int, section string, field string, value string, selector string, reason string) (statEdit, error) {
+	edit := statEdit{MatchID: matchid, Section: section, NewValue: strings.TrimSpace(value)}
+	t, ok := statTables[section]
+	if !ok {
+		return edit, invalidError(section + " is not batting, bowling or fielding")
+	}
+	for _, f := range t.fields {
+		if strings.EqualFold(f, field) {
+			edit.Field = f
+		}
+	}
+	if edit.Field == "" && strings.EqualFold(field, t.player) {
+		return edit, invalidError("rename a player with POST /api/matches/" + strconv.Itoa(matchid) + "/renames, it changes every table")
+	}
+	if edit.Field == "" {
+		return edit, invalidError(field + " is not one of " + strings.Join(t.fields, ", "))
+	}
+	if err := validateStatValue(t, edit.Field, edit.NewValue); err != nil {
+		return edit, err
+	}
+
+	rowSQL := `select ` + t.id + `, ` + t.player + `, ` + edit.Field + ` from ` + t.table + ` where matchid = ? AND TRIM(` + t.player + `) = TRIM(?)`
+	args := []interface{}{matchid, selector}
+	if id, err := strconv.Atoi(selector); err == nil && section == "fielding" {
+		rowSQL = `select ` + t.id + `, ` + t.player + `, ` + edit.Field + ` from ` + t.table + ` where matchid = ? AND ` + t.id + ` = ?`
+		args = []interface{}{matchid, id}
+	}
+	matches := make([]statEdit, 0)
+	err := queryRows(db, rowSQL, args, func(row *sql.Rows) error {
+		e := edit
+		var old sql.NullString
+		err := row.Scan(&e.RowID, &e.Player, &old)
+		e.OldValue = old.String
+		matches = append(matches, e)
+		return err
+	})
+	if err != nil {
+		return edit, err
+	}
+
+	if len(matches) == 0 {
+		return edit, notFoundError("Match ID " + strconv.Itoa(matchid) + " has no " + section + " row for " + selector)
+	}
+	if len(matches) > 1 {
+		ids := make([]string, 0)
+		for _, e := range matches {
+			ids = append(ids, strconv.Itoa(e.RowID))
+		}
+		return edit, invalidError(selector + " has " + strconv.Itoa(len(matches)) + " " + section + " rows, give the row ID instead : " + strings.Join(ids, ", "))
+	}
+	edit = matches[0]
+	if edit.OldValue == edit.NewValue {
+		return edit, invalidError(edit.Field + " for " + edit.Player + " is already " + edit.NewValue)
+	}
+	return edit, applyStatEdit(db, t, edit, reason)
+}
+
+// applyStatEdit changes the stats row, keeps the change in the audit log
+// and recalculates the points.
+func applyStatEdit(db *sql.DB, t statTable, edit statEdit, reason string) error {
+	if _, err := changeStat(db, t, edit, reason); err != nil {
+		return err
+	}
+	return calculatePoints(db)
+}
+
+// changeStat changes the stats row and keeps the change in the audit log,
+// returning its audit ID. The points are left for the caller to recalculate.
+func changeStat(db dbExecer, t statTable, edit statEdit, reason string) (int64, error) {
+	_, err := db.Exec(`UPDATE `+t.table+` SET `+edit.Field+` = ? WHERE `+t.id+` = ?`, edit.NewValue, edit.RowID)
+	if err != nil {
+		return 0, invalidError("could not change " + edit.Field + " : " + err.Error())
+	}
+	log.Println(edit.Section + " " + edit.Field + " for " + edit.Player + " in Match ID " + strconv.Itoa(edit.MatchID) + " changed from " + edit.OldValue + " to " + edit.NewValue)
+	return recordAudit(db, auditEntry{Kind: auditStat, MatchID: edit.MatchID, Player: edit.Player, Section: edit.Section, StatID: edit.RowID,
+		Field: edit.Field, OldValue: edit.OldValue, NewValue: edit.NewValue, Reason: reason})
+}
+
+func validateStatValue(t statTable, field string, value string) error {
+	isText := false
+	for _, f := range t.text {
+		isText = isText || f == field
+	}
+	switch {
+	case field == "wicketType":
+		for _, w := range wicketTypes {
+			if w == value {
+				return nil
+			}
+		}
+		return invalidError(value + " is not one of " + strings.Join(wicketTypes, ", "))
+	case field == "overs":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return invalidError(value + " is not a number of overs")
+		}
+	case isText:
+		if field == t.player && value == "" {
+			return invalidError(field + " cannot be empty")
+		}
+	default:
+		if n, err := strconv.Atoi(value); err != nil || n < 0 {
+			return invalidError(value + " is not a whole number for " + field)
+		}
+	}
+	return nil
+}
+
+func renderStatEdit(e statEdit) {
+	fmt.Println("------------------------------------------")
+	fmt.Printf("Match ID %d %s %s : %s -> %s (%s)\n", e.MatchID, e.Section, e.Field, e.OldValue, e.NewValue, e.Player)
+	fmt.Println("------------------------------------------")
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// A batter's or bowler's name is renamed in every table, not edited in one,
+// so edit refuses the name columns and points to the renames endpoint.
+func TestEditRefusesPlayerName(t *testing.T) {
+	db := openTestDB(t)
+	importTestScorecards(t, db, "scorecard.csv")
+
+	for _, tt := range []struct{ section, field, player string }{
+		{"batting", "battername", "Jai V"},
+		{"bowling", "bowlerName", "Vikas Sawkar"},
+	} {
+		_, err := editMatchStat(db, 1, tt.section, tt.field, "Somebody", tt.player, "test")
+		if !errors.Is(err, errInvalid) || !strings.Contains(err.Error(), "/api/matches/1/renames") {
+			t.Errorf("edit %s %s : %v, want the renames endpoint", tt.section, tt.field, err)
+		}
+	}
+	var renamed int
+	if err := db.QueryRow(`select count(*) from batsmen where battername = 'Somebody'`).Scan(&renamed); err != nil {
+		t.Fatal(err)
+	}
+	if renamed != 0 {
+		t.Errorf("%d batsmen rows renamed, want 0", renamed)
+	}
+
+	e, err := editMatchStat(db, 1, "batting", "runs", "45", "Jai V", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.OldValue != "23" || e.NewValue != "45" {
+		t.Errorf("edit runs %s -> %s, want 23 -> 45", e.OldValue, e.NewValue)
+	}
+}
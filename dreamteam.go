@@ -0,0 +1,216 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// Dream XI composition rules.
+const (
+	dreamTeamSize    = 11
+	dreamTeamBatters = 4
+	dreamTeamBowlers = 4
+	dreamTeamKeepers = 1
+)
+
+type dreamTeamPlayer struct {
+	Player  string `json:"player"`
+	Role    string `json:"role"`
+	Points  int    `json:"points"`
+	Matches int    `json:"matches"`
+	batter  bool
+	bowler  bool
+	keeper  bool
+}
+
+type dreamTeam struct {
+	MatchIDs    []int             `json:"matchids"`
+	XI          []dreamTeamPlayer `json:"xi"`
+	Points      int               `json:"points"`
+	Leaderboard []dreamTeamPlayer `json:"leaderboard"`
+}
+
+// matchesBetween returns the IDs of matches played between two dates in the
+// scorecard's MM/DD/YYYY format, both inclusive.
+func matchesBetween(db *sql.DB, from string, to string) ([]int, error) {
+	fromDate, err := time.Parse("01/02/2006", from)
+	if err != nil {
+		return nil, invalidError("from date " + from + " is not MM/DD/YYYY")
+	}
+	toDate, err := time.Parse("01/02/2006", to)
+	if err != nil {
+		return nil, invalidError("to date " + to + " is not MM/DD/YYYY")
+	}
+
+	matches, err := getMatches(db)
+	if err != nil {
+		return nil, err
+	}
+	matchids := make([]int, 0)
+	for _, m := range matches {
+		played, err := time.Parse("01/02/2006", m.MatchDate)
+		if err != nil {
+			continue
+		}
+		if !played.Before(fromDate) && !played.After(toDate) {
+			matchids = append(matchids, m.MatchID)
+		}
+	}
+	return matchids, nil
+}
+
+// getDreamTeam picks the highest scoring XI over the given matches that
+// still has enough batters, bowlers and one keeper.
+func getDreamTeam(db *sql.DB, matchids []int) (dreamTeam, error) {
+
+	team := dreamTeam{MatchIDs: matchids}
+	if len(matchids) == 0 {
+		return team, nil
+	}
+	ids := make([]string, 0)
+	for _, id := range matchids {
+		ids = append(ids, strconv.Itoa(id))
+	}
+	inMatches := "(" + strings.Join(ids, ",") + ")"
+
+	pointsSQL := `select Player, sum("Total Points"), count(*) from TotalMatchPoints where matchid in ` + inMatches + ` group by Player order by sum("Total Points") DESC, Player`
+	row, err := db.Query(pointsSQL)
+	if err != nil {
+		return team, err
+	}
+	for row.Next() {
+		p := dreamTeamPlayer{}
+		if err = row.Scan(&p.Player, &p.Points, &p.Matches); err != nil {
+			row.Close()
+			return team, err
+		}
+		team.Leaderboard = append(team.Leaderboard, p)
+	}
+	row.Close()
+	if err = row.Err(); err != nil {
+		return team, err
+	}
+
+	// Keepers count as batters and all-rounders as both batters and bowlers
+	roles, err := getPlayerRoles(db)
+	if err != nil {
+		return team, err
+	}
+	for i := range team.Leaderboard {
+		p := &team.Leaderboard[i]
+		p.Role = roleBatter
+		if r, ok := roles[strings.TrimSpace(p.Player)]; ok {
+			p.Role = r.Role
+		}
+		p.batter = p.Role != roleBowler
+		p.bowler = p.Role == roleBowler || p.Role == roleAllRounder
+		p.keeper = p.Role == roleKeeper
+	}
+
+	team.XI = pickDreamTeam(team.Leaderboard)
+	for _, p := range team.XI {
+		team.Points = team.Points + p.Points
+	}
+	return team, nil
+}
+
+// pickDreamTeam picks the highest scoring XI with exactly one keeper, at
+// least four bowlers and at least four batters, a role short of players
+// being filled as far as it can be. Within the keepers, batters, bowlers
+// and all-rounders the higher scorer is always the better pick, so every
+// count of all-rounders and bowlers is tried with the best of each group
+// and the batters fill the places left. candidates must be sorted by
+// points, highest first.
+func pickDreamTeam(candidates []dreamTeamPlayer) []dreamTeamPlayer {
+
+	var keepers, batters, bowlers, allRounders []dreamTeamPlayer
+	for _, p := range candidates {
+		switch {
+		case p.keeper:
+			keepers = append(keepers, p)
+		case p.batter && p.bowler:
+			allRounders = append(allRounders, p)
+		case p.bowler:
+			bowlers = append(bowlers, p)
+		default:
+			batters = append(batters, p)
+		}
+	}
+	keepers = keepers[:min(dreamTeamKeepers, len(keepers))]
+	size := min(dreamTeamSize, len(keepers)+len(batters)+len(bowlers)+len(allRounders))
+	needBowlers := min(dreamTeamBowlers, len(bowlers)+len(allRounders))
+	needBatters := min(dreamTeamBatters, len(keepers)+len(batters)+len(allRounders))
+
+	// prefix sums, total[n] is the points of the best n of a group
+	totals := func(group []dreamTeamPlayer) []int {
+		total := make([]int, len(group)+1)
+		for i, p := range group {
+			total[i+1] = total[i] + p.Points
+		}
+		return total
+	}
+	batterTotal, bowlerTotal, allRounderTotal := totals(batters), totals(bowlers), totals(allRounders)
+
+	best, bestA, bestW, bestB := -1, 0, 0, 0
+	for a := 0; a <= len(allRounders); a++ {
+		for w := 0; w <= len(bowlers); w++ {
+			b := size - len(keepers) - a - w
+			if b < 0 || b > len(batters) || a+w < needBowlers || len(keepers)+b+a < needBatters {
+				continue
+			}
+			if points := allRounderTotal[a] + bowlerTotal[w] + batterTotal[b]; points > best {
+				best, bestA, bestW, bestB = points, a, w, b
+			}
+		}
+	}
+
+	picked := make(map[string]bool)
+	for _, group := range [][]dreamTeamPlayer{keepers, allRounders[:bestA], bowlers[:bestW], batters[:bestB]} {
+		for _, p := range group {
+			picked[p.Player] = true
+		}
+	}
+	xi := make([]dreamTeamPlayer, 0, size)
+	for _, p := range candidates {
+		if best >= 0 && picked[p.Player] {
+			xi = append(xi, p)
+		}
+	}
+	return xi
+}
+
+func renderDreamTeam(team dreamTeam) {
+
+	inXI := make(map[string]bool)
+	xi := table.NewWriter()
+	xi.AppendHeader(table.Row{"S.No", "Player", "Role", "Points"})
+	for i, p := range team.XI {
+		xi.AppendRow(table.Row{i + 1, p.Player, p.Role, p.Points})
+		inXI[p.Player] = true
+	}
+	xi.AppendFooter(table.Row{"", "Total", "", team.Points})
+
+	leaderboard := table.NewWriter()
+	leaderboard.AppendHeader(table.Row{"S.No", "Player", "Role", "Matches", "Points", "XI"})
+	for i, p := range team.Leaderboard {
+		selected := ""
+		if inXI[p.Player] {
+			selected = "*"
+		}
+		leaderboard.AppendRow(table.Row{i + 1, p.Player, p.Role, p.Matches, p.Points, selected})
+	}
+
+	fmt.Println("------------------------------------------")
+	fmt.Println("Dream XI")
+	fmt.Println("------------------------------------------")
+	fmt.Println(xi.Render())
+	fmt.Println("------------------------------------------")
+	fmt.Println("Leaderboard")
+	fmt.Println("------------------------------------------")
+	fmt.Println(leaderboard.Render())
+}
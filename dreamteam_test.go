@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+// testCandidates makes dream team candidates from role and points pairs,
+// named by role and position, sorted the way getDreamTeam sorts them.
+func testCandidates(players ...interface{}) []dreamTeamPlayer {
+	candidates := make([]dreamTeamPlayer, 0)
+	for i := 0; i < len(players); i = i + 2 {
+		role, points := players[i].(string), players[i+1].(int)
+		p := dreamTeamPlayer{Player: fmt.Sprintf("%s %d", role, i/2+1), Role: role, Points: points}
+		p.batter = role != roleBowler
+		p.bowler = role == roleBowler || role == roleAllRounder
+		p.keeper = role == roleKeeper
+		candidates = append(candidates, p)
+	}
+	for i := range candidates {
+		for j := i + 1; j < len(candidates); j++ {
+			if candidates[j].Points > candidates[i].Points {
+				candidates[i], candidates[j] = candidates[j], candidates[i]
+			}
+		}
+	}
+	return candidates
+}
+
+func TestPickDreamTeam(t *testing.T) {
+	tests := []struct {
+		name                      string
+		candidates                []dreamTeamPlayer
+		size, points              int
+		keepers, batters, bowlers int
+	}{
+		{
+			// picking the best players for each role in turn takes the second
+			// keeper as a batter, and no single swap gets back to one keeper
+			name: "two keepers",
+			candidates: testCandidates(roleKeeper, 90, roleKeeper, 80,
+				roleBatter, 10, roleBatter, 10, roleBatter, 10,
+				roleBowler, 50, roleBowler, 50, roleBowler, 50, roleBowler, 50,
+				roleBowler, 50, roleBowler, 50, roleBowler, 50, roleBowler, 50),
+			size: 11, points: 470, keepers: 1, batters: 4, bowlers: 7,
+		},
+		{
+			// the all-rounder is the fourth bowler and still counts as a batter
+			name: "all-rounder",
+			candidates: testCandidates(roleKeeper, 5, roleAllRounder, 20,
+				roleBatter, 60, roleBatter, 60, roleBatter, 60, roleBatter, 60, roleBatter, 60, roleBatter, 60, roleBatter, 60,
+				roleBowler, 30, roleBowler, 30, roleBowler, 30),
+			size: 11, points: 475, keepers: 1, batters: 8, bowlers: 4,
+		},
+		{
+			name:       "short of bowlers and keepers",
+			candidates: testCandidates(roleBatter, 40, roleBatter, 30, roleBowler, 1, roleBowler, 2),
+			size:       4, points: 73, keepers: 0, batters: 2, bowlers: 2,
+		},
+	}
+	for _, tt := range tests {
+		xi := pickDreamTeam(tt.candidates)
+		points, keepers, batters, bowlers := 0, 0, 0, 0
+		for _, p := range xi {
+			points = points + p.Points
+			if p.keeper {
+				keepers = keepers + 1
+			}
+			if p.batter {
+				batters = batters + 1
+			}
+			if p.bowler {
+				bowlers = bowlers + 1
+			}
+		}
+		if len(xi) != tt.size || points != tt.points || keepers != tt.keepers || batters != tt.batters || bowlers != tt.bowlers {
+			t.Errorf("%s : %d players %d points, %d keepers %d batters %d bowlers, want %d %d, %d %d %d", tt.name,
+				len(xi), points, keepers, batters, bowlers, tt.size, tt.points, tt.keepers, tt.batters, tt.bowlers)
+		}
+	}
+}
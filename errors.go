@@ -0,0 +1,41 @@
+package main
+
+import (
+	"errors"
+)
+
+// errNotFound, errInvalid and errConflict mark an error as about what was
+// asked for, a player who is not in the match, a value that is not allowed
+// or a match that is already imported, rather than the database failing.
+// The API answers them with 404, 400 and 409.
+var (
+	errNotFound = errors.New("not found")
+	errInvalid  = errors.New("invalid")
+	errConflict = errors.New("conflict")
+)
+
+// requestError is an error whose kind is errNotFound, errInvalid or errConflict.
+type requestError struct {
+	kind    error
+	message string
+}
+
+func (e *requestError) Error() string {
+	return e.message
+}
+
+func (e *requestError) Unwrap() error {
+	return e.kind
+}
+
+func notFoundError(message string) error {
+	return &requestError{kind: errNotFound, message: message}
+}
+
+func invalidError(message string) error {
+	return &requestError{kind: errInvalid, message: message}
+}
+
+func conflictError(message string) error {
+	return &requestError{kind: errConflict, message: message}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// explainPoints lists every scoring event behind a player's points for a
+// match, worked out from the stored batsmen, bowlers and fielders rows.
+func explainPoints(db *sql.DB, matchid int, playerName string) {
+
+	var battername string
+	var runs, balls, fours, sixers, notout int
+	batSQL := "select battername, runs, balls, fours, sixers, Notout from batsmen where matchid = ? AND TRIM(battername) = TRIM(?)"
+	err := db.QueryRow(batSQL, matchid, playerName).Scan(&battername, &runs, &balls, &fours, &sixers, &notout)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			fmt.Println("Player " + playerName + " did not play in Match ID " + strconv.Itoa(matchid))
+			return
+		}
+		log.Fatal(err)
+	}
+
+	events := make([]string, 0)
+	total := 0
+	addEvent := func(points int, event string) {
+		events = append(events, event)
+		total = total + points
+	}
+
+	// Batting
+	if runs > 0 {
+		addEvent(runs*activeRules.Run, fmt.Sprintf("%d runs × %d = %d", runs, activeRules.Run, runs*activeRules.Run))
+	}
+	if fours > 0 {
+		addEvent(fours*activeRules.Four, fmt.Sprintf("%d fours × %d = %d", fours, activeRules.Four, fours*activeRules.Four))
+	}
+	if notout == 1 {
+		addEvent(activeRules.NotOut, fmt.Sprintf("not out = %+d", activeRules.NotOut))
+	}
+	if isDuck(runs, balls) {
+		addEvent(activeRules.Duck, fmt.Sprintf("duck (%d balls) = %+d", balls, activeRules.Duck))
+	}
+
+	// Bowling
+	var overs string
+	var maidens, runsGiven, wickets, wides, noBalls int
+	bowlSQL := "select overs, Maidens, RunsGiven, wickets, Wides, NoBalls from bowlers where matchid = ? AND bowlerName = ?"
+	err = db.QueryRow(bowlSQL, matchid, battername).Scan(&overs, &maidens, &runsGiven, &wickets, &wides, &noBalls)
+	if err != nil && err != sql.ErrNoRows {
+		log.Fatal(err)
+	}
+	if err == nil {
+		if wickets > 0 {
+			addEvent(wickets*activeRules.Wicket, fmt.Sprintf("%d wickets × %d = %d", wickets, activeRules.Wicket, wickets*activeRules.Wicket))
+		}
+		if maidens > 0 {
+			addEvent(maidens*activeRules.Maiden, fmt.Sprintf("%d maidens × %d = %d", maidens, activeRules.Maiden, maidens*activeRules.Maiden))
+		}
+		oversBowled, _ := strconv.ParseFloat(strings.TrimSpace(overs), 64)
+		if oversBowled > 0 {
+			economy := float64(runsGiven) / oversBowled
+			if economy <= activeRules.GoodEconomyMax {
+				addEvent(activeRules.GoodEconomy, fmt.Sprintf("economy %.1f ≤ %g = %+d", economy, activeRules.GoodEconomyMax, activeRules.GoodEconomy))
+			} else if economy >= activeRules.PoorEconomyMin {
+				addEvent(activeRules.PoorEconomy, fmt.Sprintf("economy %.1f ≥ %g = %+d", economy, activeRules.PoorEconomyMin, activeRules.PoorEconomy))
+			}
+			if wides+noBalls > 0 {
+				addEvent((wides+noBalls)*activeRules.Extra, fmt.Sprintf("%d wides/no balls × %d = %d", wides+noBalls, activeRules.Extra, (wides+noBalls)*activeRules.Extra))
+			} else {
+				addEvent(activeRules.NoExtras, fmt.Sprintf("no wides/no balls = %+d", activeRules.NoExtras))
+			}
+		}
+	}
+
+	// Fielding, and batters bowled
+	role, err := getPlayerRole(db, battername)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fieldSQL := "select Batsman, wicketType, fieldername, bowlername, bowled, catches, runouts from fielders where matchid = ? AND (fieldername = ? OR bowlername = ?)"
+	row, err := db.Query(fieldSQL, matchid, battername, battername)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer row.Close()
+	for row.Next() {
+		var batsman, wicketType, fieldername, bowlername string
+		var bowled, catches, runouts int
+		row.Scan(&batsman, &wicketType, &fieldername, &bowlername, &bowled, &catches, &runouts)
+
+		if bowlername == battername && bowled > 0 {
+			addEvent(bowled*activeRules.Bowled, fmt.Sprintf("bowled %s = %d", batsman, bowled*activeRules.Bowled))
+		}
+		if fieldername != battername {
+			continue
+		}
+		if catches > 0 {
+			switch wicketType {
+			case "Caught&Bowled":
+				addEvent(catches*activeRules.Catch, fmt.Sprintf("caught & bowled %s = %d", batsman, catches*activeRules.Catch))
+			case "CaughtBehind":
+				addEvent(catches*activeRules.Catch, fmt.Sprintf("caught behind %s = %d", batsman, catches*activeRules.Catch))
+			default:
+				addEvent(catches*activeRules.Catch, fmt.Sprintf("caught %s = %d", batsman, catches*activeRules.Catch))
+			}
+			for _, bonus := range roleBonuses(activeRules) {
+				if bonus.Role == role && bonus.WicketType == wicketType {
+					addEvent(catches*bonus.Points, fmt.Sprintf("%s %s = %+d", bonus.Label, batsman, catches*bonus.Points))
+				}
+			}
+		}
+		if runouts > 0 {
+			if wicketType == "RunOut-DirectHit" {
+				addEvent(runouts*activeRules.DirectHit, fmt.Sprintf("run out %s (direct hit) = %d", batsman, runouts*activeRules.DirectHit))
+			} else {
+				addEvent(runouts*activeRules.RunOut, fmt.Sprintf("run out %s = %d", batsman, runouts*activeRules.RunOut))
+			}
+		}
+	}
+
+	// Match result
+	var result string
+	err = db.QueryRow("select Result from match where matchid = ?", matchid).Scan(&result)
+	if err != nil && err != sql.ErrNoRows {
+		log.Fatal(err)
+	}
+	if strings.HasPrefix(strings.ToLower(result), "phoenix won") {
+		addEvent(activeRules.MatchWon, fmt.Sprintf("match won (%s) = %+d", result, activeRules.MatchWon))
+	}
+
+	// Manual adjustments, latest entry per field wins
+	adjSQL := "select field, value, enteredBy, enteredAt from adjustments where matchid = ? AND TRIM(player) = TRIM(?) order by rowid"
+	adjRows, err := db.Query(adjSQL, matchid, battername)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer adjRows.Close()
+	latest := make(map[string]string)
+	latestValue := make(map[string]int)
+	for adjRows.Next() {
+		var field, enteredBy, enteredAt string
+		var value int
+		adjRows.Scan(&field, &value, &enteredBy, &enteredAt)
+		latestValue[field] = value
+		latest[field] = "entered by " + enteredBy + " on " + enteredAt
+	}
+	if value := latestValue["OneRunOvers"]; value != 0 {
+		addEvent(value*activeRules.OneRunOver, fmt.Sprintf("%d one run overs × %d = %d (%s)", value, activeRules.OneRunOver, value*activeRules.OneRunOver, latest["OneRunOvers"]))
+	}
+	if value := latestValue["DropCatches"]; value != 0 {
+		addEvent(value*activeRules.DropCatch, fmt.Sprintf("%d dropped catches × %d = %d (%s)", value, activeRules.DropCatch, value*activeRules.DropCatch, latest["DropCatches"]))
+	}
+
+	fmt.Println("------------------------------------------")
+	fmt.Println("Points for " + battername + " in Match ID " + strconv.Itoa(matchid))
+	fmt.Println("------------------------------------------")
+	for _, event := range events {
+		fmt.Println("  " + event)
+	}
+	fmt.Println("------------------------------------------")
+	fmt.Println("Total = " + strconv.Itoa(total))
+
+	var storedTotal int
+	err = db.QueryRow(`select "Total Points" from TotalMatchPoints where matchid = ? AND Player = ?`, matchid, battername).Scan(&storedTotal)
+	if err == nil && storedTotal != total {
+		fmt.Println("Stored Total Points = " + strconv.Itoa(storedTotal) + " (differs from the events above)")
+	}
+
+	// Manual changes behind the figures above, from the audit log
+	changes, err := getPlayerAuditLog(db, matchid, battername)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(changes) > 0 {
+		fmt.Println("------------------------------------------")
+		fmt.Println("Changes")
+		for _, e := range changes {
+			fmt.Println("  " + describeAudit(e))
+		}
+	}
+}
@@ -0,0 +1,571 @@
+package main
+
+import (
+	"bufio"
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// Fantasy entry rules: a member picks 11 players before a match, with a
+// captain and vice-captain whose points are multiplied.
+const (
+	fantasyTeamSize          = 11
+	fantasyCaptainMultiplier = 2.0
+	fantasyViceMultiplier    = 1.5
+)
+
+// Fantasy draft rules: the XI has to fit in the budget at the prices before
+// the match, and can change at most fantasyTransfers players from the
+// member's previous entry. Prices follow the average points over the last
+// fantasyFormMatches matches, one credit per fantasyPointsPerCredit points
+// above the minimum price.
+const (
+	fantasyBudget          = 100.0
+	fantasyTransfers       = 3
+	fantasyFormMatches     = 3
+	fantasyMinPrice        = 5.0
+	fantasyMaxPrice        = 12.0
+	fantasyNewPlayerPrice  = 6.0
+	fantasyPointsPerCredit = 10.0
+)
+
+type fantasyEntry struct {
+	EntryID     int      `json:"entryid"`
+	Member      string   `json:"member"`
+	MatchID     int      `json:"matchid,omitempty"`
+	MatchDate   string   `json:"matchDate"`
+	Captain     string   `json:"captain"`
+	ViceCaptain string   `json:"viceCaptain"`
+	Players     []string `json:"players"`
+	EnteredAt   string   `json:"enteredAt"`
+	Cost        float64  `json:"cost"`
+	Transfers   int      `json:"transfers"`
+	Points      float64  `json:"points"`
+}
+
+type fantasyPrice struct {
+	Player  string  `json:"player"`
+	Form    float64 `json:"form"`
+	Matches int     `json:"matches"`
+	Price   float64 `json:"price"`
+}
+
+type fantasyStanding struct {
+	Member  string  `json:"member"`
+	Entries int     `json:"entries"`
+	Points  float64 `json:"points"`
+}
+
+// validateFantasyEntry checks the entry has 11 different players and a
+// captain and vice-captain from among them.
+func validateFantasyEntry(entry fantasyEntry) error {
+	if strings.TrimSpace(entry.Member) == "" {
+		return invalidError("member name is required")
+	}
+	if _, err := time.Parse("01/02/2006", entry.MatchDate); err != nil {
+		return invalidError("match date " + entry.MatchDate + " is not MM/DD/YYYY")
+	}
+	if len(entry.Players) != fantasyTeamSize {
+		return invalidError(fmt.Sprintf("an entry needs %d players, got %d", fantasyTeamSize, len(entry.Players)))
+	}
+	seen := make(map[string]bool)
+	for _, p := range entry.Players {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			return invalidError("player names can not be empty")
+		}
+		if seen[p] {
+			return invalidError(p + " is picked more than once")
+		}
+		seen[p] = true
+	}
+	if !seen[strings.TrimSpace(entry.Captain)] {
+		return invalidError("captain " + entry.Captain + " is not one of the picked players")
+	}
+	if !seen[strings.TrimSpace(entry.ViceCaptain)] {
+		return invalidError("vice-captain " + entry.ViceCaptain + " is not one of the picked players")
+	}
+	if strings.TrimSpace(entry.Captain) == strings.TrimSpace(entry.ViceCaptain) {
+		return invalidError("captain and vice-captain must be different players")
+	}
+	return nil
+}
+
+// saveFantasyEntry stores a member's entry for a match, replacing any
+// earlier entry by the same member for that match. The entry names its
+// match by ID, or by date when the match is not imported yet or was the
+// only one played that day.
+func saveFantasyEntry(db *sql.DB, entry fantasyEntry) (fantasyEntry, error) {
+	if entry.MatchID != 0 {
+		m, err := getMatchDetails(db, entry.MatchID)
+		if err != nil {
+			return entry, err
+		}
+		entry.MatchDate = m.MatchDate
+	}
+	if err := validateFantasyEntry(entry); err != nil {
+		return entry, err
+	}
+	if entry.MatchID == 0 {
+		matchids, err := matchesOn(db, entry.MatchDate)
+		if err != nil {
+			return entry, err
+		}
+		if len(matchids) > 1 {
+			return entry, invalidError(fmt.Sprintf("%d matches were played on %s, give the Match ID", len(matchids), entry.MatchDate))
+		}
+		if len(matchids) == 1 {
+			entry.MatchID = matchids[0]
+		}
+	}
+	if err := validateFantasyDraft(db, entry); err != nil {
+		return entry, err
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return entry, err
+	}
+	defer tx.Rollback()
+
+	// an entry made by date before the match was imported is the same entry
+	sameEntrySQL := `TRIM(member) = TRIM(?) AND (matchid = ? OR (matchid IS NULL AND matchDate = ?))`
+	deletePicksSQL := `DELETE FROM fantasy_picks WHERE entryid IN (SELECT entryid FROM fantasy_entries WHERE ` + sameEntrySQL + `)`
+	if _, err = tx.Exec(deletePicksSQL, entry.Member, entry.MatchID, entry.MatchDate); err != nil {
+		return entry, err
+	}
+	if _, err = tx.Exec(`DELETE FROM fantasy_entries WHERE `+sameEntrySQL, entry.Member, entry.MatchID, entry.MatchDate); err != nil {
+		return entry, err
+	}
+
+	var matchid interface{}
+	if entry.MatchID != 0 {
+		matchid = entry.MatchID
+	}
+	insertEntrySQL := `INSERT INTO fantasy_entries (member,matchid,matchDate,captain,viceCaptain,enteredAt) VALUES (TRIM(?),?,?,TRIM(?),TRIM(?),?)`
+	res, err := tx.Exec(insertEntrySQL, entry.Member, matchid, entry.MatchDate, entry.Captain, entry.ViceCaptain, time.Now().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return entry, err
+	}
+	entryid, err := res.LastInsertId()
+	if err != nil {
+		return entry, err
+	}
+
+	insertPickSQL := `INSERT INTO fantasy_picks (entryid,player) VALUES (?,TRIM(?))`
+	statement, err := tx.Prepare(insertPickSQL) // Prepare statement.
+	if err != nil {
+		return entry, err
+	}
+	defer statement.Close()
+	for _, p := range entry.Players {
+		if _, err = statement.Exec(entryid, p); err != nil {
+			return entry, err
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return entry, err
+	}
+	log.Println("Fantasy entry saved for " + entry.Member + " ...")
+	entry.EntryID = int(entryid)
+	return entry, nil
+}
+
+// matchesOn returns the IDs of the imported matches played on a date.
+func matchesOn(db *sql.DB, matchDate string) ([]int, error) {
+	matchids := make([]int, 0)
+	err := queryRows(db, `select matchid from "match" where matchDate = ? order by matchid`, []interface{}{matchDate}, func(row *sql.Rows) error {
+		var id int
+		err := row.Scan(&id)
+		matchids = append(matchids, id)
+		return err
+	})
+	return matchids, err
+}
+
+// getFantasyEntries returns the entries for a match, for every match on a
+// date when matchid is 0, or for all matches when both are empty, scored
+// from TotalMatchPoints. An entry made by date before its match was
+// imported counts for the match when it was the only one that day.
+func getFantasyEntries(db *sql.DB, matchid int, matchDate string) ([]fantasyEntry, error) {
+
+	entriesSQL := `select entryid, member, coalesce(matchid, 0), matchDate, captain, viceCaptain, enteredAt from fantasy_entries
+		where (? = 0 OR matchid = ? OR (matchid IS NULL AND matchDate = ?)) AND (? = '' OR matchDate = ?) order by entryid`
+	entries := make([]fantasyEntry, 0)
+	args := []interface{}{matchid, matchid, matchDate, matchDate, matchDate}
+	err := queryRows(db, entriesSQL, args, func(row *sql.Rows) error {
+		e := fantasyEntry{}
+		err := row.Scan(&e.EntryID, &e.Member, &e.MatchID, &e.MatchDate, &e.Captain, &e.ViceCaptain, &e.EnteredAt)
+		entries = append(entries, e)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	played := make(map[string][]int)
+	for i := range entries {
+		if entries[i].MatchID != 0 {
+			continue
+		}
+		date := entries[i].MatchDate
+		if _, ok := played[date]; !ok {
+			if played[date], err = matchesOn(db, date); err != nil {
+				return nil, err
+			}
+		}
+		if len(played[date]) == 1 {
+			entries[i].MatchID = played[date][0]
+		}
+	}
+	scored := make([]fantasyEntry, 0, len(entries))
+	for _, e := range entries {
+		if matchid == 0 || e.MatchID == matchid {
+			scored = append(scored, e)
+		}
+	}
+	entries = scored
+
+	for i := range entries {
+		if entries[i].Players, err = getFantasyPicks(db, entries[i].EntryID); err != nil {
+			return nil, err
+		}
+		if entries[i].Points, err = scoreFantasyEntry(db, entries[i]); err != nil {
+			return nil, err
+		}
+		if entries[i].Cost, err = fantasyEntryCost(db, entries[i]); err != nil {
+			return nil, err
+		}
+		if entries[i].Transfers, err = fantasyEntryTransfers(db, entries[i]); err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(entries, func(a, b int) bool { return entries[a].Points > entries[b].Points })
+	return entries, nil
+}
+
+// validateFantasyDraft checks every pick has batted in an imported match,
+// so a misspelt name is not a cheap pick that scores nothing, and the entry
+// is within budget and within the transfer limit from the member's previous
+// entry.
+func validateFantasyDraft(db *sql.DB, entry fantasyEntry) error {
+	players, err := getPlayers(db)
+	if err != nil {
+		return err
+	}
+	known := make(map[string]bool)
+	for _, p := range players {
+		known[p] = true
+	}
+	for _, p := range entry.Players {
+		if !known[strings.TrimSpace(p)] {
+			return invalidError(strings.TrimSpace(p) + " is not in any imported match, check the name")
+		}
+	}
+	cost, err := fantasyEntryCost(db, entry)
+	if err != nil {
+		return err
+	}
+	if cost > fantasyBudget {
+		return invalidError(fmt.Sprintf("entry costs %.1f, over the budget of %.1f", cost, fantasyBudget))
+	}
+	transfers, err := fantasyEntryTransfers(db, entry)
+	if err != nil {
+		return err
+	}
+	if transfers > fantasyTransfers {
+		return invalidError(fmt.Sprintf("entry makes %d transfers from the previous entry, at most %d are allowed", transfers, fantasyTransfers))
+	}
+	return nil
+}
+
+func fantasyEntryCost(db *sql.DB, entry fantasyEntry) (float64, error) {
+	cost := 0.0
+	for _, p := range entry.Players {
+		price, err := getFantasyPrice(db, p, entry.MatchDate)
+		if err != nil {
+			return 0, err
+		}
+		cost = cost + price.Price
+	}
+	return cost, nil
+}
+
+// fantasyEntryTransfers counts the players in the entry who were not in the
+// member's latest entry before it, a member's first entry makes none.
+func fantasyEntryTransfers(db *sql.DB, entry fantasyEntry) (int, error) {
+	entryDate, _ := time.Parse("01/02/2006", entry.MatchDate)
+	// matches on the same day are in match ID order
+	before := func(playedA time.Time, matchA int, playedB time.Time, matchB int) bool {
+		if playedA.Equal(playedB) {
+			return matchA != 0 && matchB != 0 && matchA < matchB
+		}
+		return playedA.Before(playedB)
+	}
+
+	previousID, previousMatch := 0, 0
+	var previousDate time.Time
+	entriesSQL := "select entryid, coalesce(matchid, 0), matchDate from fantasy_entries where TRIM(member) = TRIM(?)"
+	err := queryRows(db, entriesSQL, []interface{}{entry.Member}, func(row *sql.Rows) error {
+		var entryid, matchid int
+		var matchDate string
+		if err := row.Scan(&entryid, &matchid, &matchDate); err != nil {
+			return err
+		}
+		played, err := time.Parse("01/02/2006", matchDate)
+		if err != nil || !before(played, matchid, entryDate, entry.MatchID) {
+			return nil
+		}
+		if previousID == 0 || before(previousDate, previousMatch, played, matchid) {
+			previousID, previousMatch, previousDate = entryid, matchid, played
+		}
+		return nil
+	})
+	if err != nil || previousID == 0 {
+		return 0, err
+	}
+
+	picks, err := getFantasyPicks(db, previousID)
+	if err != nil {
+		return 0, err
+	}
+	previous := make(map[string]bool)
+	for _, p := range picks {
+		previous[p] = true
+	}
+	transfers := 0
+	for _, p := range entry.Players {
+		if !previous[strings.TrimSpace(p)] {
+			transfers = transfers + 1
+		}
+	}
+	return transfers, nil
+}
+
+// getFantasyPrice prices a player for a match from their average points in
+// their last fantasyFormMatches matches played before matchDate.
+func getFantasyPrice(db *sql.DB, playerName string, matchDate string) (fantasyPrice, error) {
+	price := fantasyPrice{Player: strings.TrimSpace(playerName), Price: fantasyNewPlayerPrice}
+	entryDate, _ := time.Parse("01/02/2006", matchDate)
+
+	points, err := getPlayerMatchPoints(db, playerName)
+	if err != nil {
+		return price, err
+	}
+	form := make([]playerMatchPoints, 0)
+	for _, p := range points {
+		played, err := time.Parse("01/02/2006", p.MatchDate)
+		if err == nil && played.Before(entryDate) {
+			form = append(form, p)
+		}
+	}
+	sort.SliceStable(form, func(a, b int) bool {
+		playedA, _ := time.Parse("01/02/2006", form[a].MatchDate)
+		playedB, _ := time.Parse("01/02/2006", form[b].MatchDate)
+		return playedA.After(playedB)
+	})
+	if len(form) > fantasyFormMatches {
+		form = form[:fantasyFormMatches]
+	}
+	if len(form) == 0 {
+		return price, nil
+	}
+
+	total := 0
+	for _, p := range form {
+		total = total + p.Points
+	}
+	price.Matches = len(form)
+	price.Form = float64(total) / float64(len(form))
+	price.Price = math.Max(fantasyMinPrice, math.Min(fantasyMaxPrice, fantasyMinPrice+price.Form/fantasyPointsPerCredit))
+	// prices move in half credits
+	price.Price = math.Round(price.Price*2) / 2
+	return price, nil
+}
+
+// getFantasyPrices prices every player who has played for a match date.
+func getFantasyPrices(db *sql.DB, matchDate string) ([]fantasyPrice, error) {
+
+	players := make([]string, 0)
+	err := queryRows(db, "select distinct TRIM(Player) from TotalMatchPoints", nil, func(row *sql.Rows) error {
+		var player string
+		err := row.Scan(&player)
+		players = append(players, player)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	prices := make([]fantasyPrice, 0)
+	for _, p := range players {
+		price, err := getFantasyPrice(db, p, matchDate)
+		if err != nil {
+			return nil, err
+		}
+		prices = append(prices, price)
+	}
+	sort.Slice(prices, func(a, b int) bool {
+		if prices[a].Price != prices[b].Price {
+			return prices[a].Price > prices[b].Price
+		}
+		return prices[a].Player < prices[b].Player
+	})
+	return prices, nil
+}
+
+func getFantasyPicks(db *sql.DB, entryid int) ([]string, error) {
+	players := make([]string, 0)
+	err := queryRows(db, "select player from fantasy_picks where entryid = ? order by rowid", []interface{}{entryid}, func(row *sql.Rows) error {
+		var player string
+		err := row.Scan(&player)
+		players = append(players, player)
+		return err
+	})
+	return players, err
+}
+
+// scoreFantasyEntry adds up the picked players' points for the match,
+// doubling the captain's and adding half again to the vice-captain's. An
+// entry whose match is not known scores nothing.
+func scoreFantasyEntry(db *sql.DB, entry fantasyEntry) (float64, error) {
+
+	if entry.MatchID == 0 {
+		return 0, nil
+	}
+	pointsSQL := `select "Total Points" from TotalMatchPoints where matchid = ? AND TRIM(Player) = TRIM(?)`
+	statement, err := db.Prepare(pointsSQL)
+	if err != nil {
+		return 0, err
+	}
+	defer statement.Close()
+
+	total := 0.0
+	for _, p := range entry.Players {
+		var points int
+		err = statement.QueryRow(entry.MatchID, p).Scan(&points)
+		if err != nil && err != sql.ErrNoRows {
+			return 0, err
+		}
+		switch p {
+		case entry.Captain:
+			total = total + float64(points)*fantasyCaptainMultiplier
+		case entry.ViceCaptain:
+			total = total + float64(points)*fantasyViceMultiplier
+		default:
+			total = total + float64(points)
+		}
+	}
+	return total, nil
+}
+
+// getFantasyLeaderboard totals every member's entries over the matches of a
+// series, or all matches when series is empty.
+func getFantasyLeaderboard(db *sql.DB, series string) ([]fantasyStanding, error) {
+
+	matches, err := getMatches(db)
+	if err != nil {
+		return nil, err
+	}
+	seriesMatches := make(map[int]bool)
+	for _, m := range matches {
+		if series == "" || m.Series == series {
+			seriesMatches[m.MatchID] = true
+		}
+	}
+
+	entries, err := getFantasyEntries(db, 0, "")
+	if err != nil {
+		return nil, err
+	}
+	members := make(map[string]*fantasyStanding)
+	for _, e := range entries {
+		if !seriesMatches[e.MatchID] {
+			continue
+		}
+		if _, ok := members[e.Member]; !ok {
+			members[e.Member] = &fantasyStanding{Member: e.Member}
+		}
+		members[e.Member].Entries = members[e.Member].Entries + 1
+		members[e.Member].Points = members[e.Member].Points + e.Points
+	}
+
+	leaderboard := make([]fantasyStanding, 0)
+	for _, m := range members {
+		leaderboard = append(leaderboard, *m)
+	}
+	sort.Slice(leaderboard, func(a, b int) bool {
+		if leaderboard[a].Points != leaderboard[b].Points {
+			return leaderboard[a].Points > leaderboard[b].Points
+		}
+		return leaderboard[a].Member < leaderboard[b].Member
+	})
+	return leaderboard, nil
+}
+
+// processFantasyEntry prompts for a member's entry, the same way the
+// corrections are entered after an import.
+func processFantasyEntry(db *sql.DB, matchid int, matchDate string) {
+	reader := bufio.NewReader(os.Stdin)
+	prompt := func(question string) string {
+		fmt.Print(question)
+		text, _ := reader.ReadString('\n')
+		return strings.TrimSpace(strings.Replace(text, "\n", "", -1))
+	}
+
+	entry := fantasyEntry{MatchID: matchid, MatchDate: matchDate}
+	entry.Member = prompt("Member Name ? ")
+	for i := 0; i < fantasyTeamSize; i++ {
+		entry.Players = append(entry.Players, prompt(fmt.Sprintf("Player %d ? ", i+1)))
+	}
+	entry.Captain = prompt("Captain (2x) ? ")
+	entry.ViceCaptain = prompt("Vice-Captain (1.5x) ? ")
+
+	if _, err := saveFantasyEntry(db, entry); err != nil {
+		log.Println("Entry not saved : " + err.Error())
+		os.Exit(1)
+	}
+}
+
+func renderFantasyEntries(entries []fantasyEntry) {
+	t := table.NewWriter()
+	t.AppendHeader(table.Row{"Rank", "Member", "Match Date", "Captain", "Vice-Captain", "Cost", "Transfers", "Points"})
+	for i, e := range entries {
+		t.AppendRow(table.Row{i + 1, e.Member, e.MatchDate, e.Captain, e.ViceCaptain, fmt.Sprintf("%.1f", e.Cost), e.Transfers, fmt.Sprintf("%.1f", e.Points)})
+	}
+	fmt.Println("------------------------------------------")
+	fmt.Println("Fantasy Entries")
+	fmt.Println("------------------------------------------")
+	fmt.Println(t.Render())
+}
+
+func renderFantasyPrices(matchDate string, prices []fantasyPrice) {
+	t := table.NewWriter()
+	t.AppendHeader(table.Row{"S.No", "Player", "Matches", "Form", "Price"})
+	for i, p := range prices {
+		t.AppendRow(table.Row{i + 1, p.Player, p.Matches, fmt.Sprintf("%.1f", p.Form), fmt.Sprintf("%.1f", p.Price)})
+	}
+	fmt.Println("------------------------------------------")
+	fmt.Printf("Fantasy Prices for %s (budget %.1f, %d transfers)\n", matchDate, fantasyBudget, fantasyTransfers)
+	fmt.Println("------------------------------------------")
+	fmt.Println(t.Render())
+}
+
+func renderFantasyLeaderboard(leaderboard []fantasyStanding) {
+	t := table.NewWriter()
+	t.AppendHeader(table.Row{"Rank", "Member", "Entries", "Points"})
+	for i, m := range leaderboard {
+		t.AppendRow(table.Row{i + 1, m.Member, m.Entries, fmt.Sprintf("%.1f", m.Points)})
+	}
+	fmt.Println("------------------------------------------")
+	fmt.Println("Fantasy Members Leaderboard")
+	fmt.Println("------------------------------------------")
+	fmt.Println(t.Render())
+}
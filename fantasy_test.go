@@ -0,0 +1,96 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+// On a double-header each entry is scored from its own match, and an entry
+// by date alone is refused as it could be for either match.
+func TestFantasyDoubleHeader(t *testing.T) {
+	db := openTestDB(t)
+	importTestScorecards(t, db, "scorecard.csv", "Mar3-20.csv")
+	if _, err := db.Exec(`UPDATE "match" SET matchDate = (select matchDate from "match" where matchid = 1) WHERE matchid = 2`); err != nil {
+		t.Fatal(err)
+	}
+	if err := calculatePoints(db); err != nil {
+		t.Fatal(err)
+	}
+	m, err := getMatchDetails(db, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	players := make([]string, 0)
+	err = queryRows(db, `select TRIM(Player) from TotalMatchPoints where matchid = 1 order by Player limit 11`, nil, func(row *sql.Rows) error {
+		var player string
+		err := row.Scan(&player)
+		players = append(players, player)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := fantasyEntry{Member: "Ravi", MatchDate: m.MatchDate, Players: players, Captain: players[0], ViceCaptain: players[1]}
+	if _, err := saveFantasyEntry(db, entry); !errors.Is(err, errInvalid) {
+		t.Errorf("entry by date on a double-header : %v, want it refused", err)
+	}
+
+	for _, matchid := range []int{1, 2} {
+		entry.MatchID, entry.MatchDate = matchid, ""
+		if _, err := saveFantasyEntry(db, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, matchid := range []int{1, 2} {
+		want := 0.0
+		for _, p := range players {
+			var points int
+			err := db.QueryRow(`select "Total Points" from TotalMatchPoints where matchid = ? AND TRIM(Player) = ?`, matchid, p).Scan(&points)
+			if err != nil && err != sql.ErrNoRows {
+				t.Fatal(err)
+			}
+			switch p {
+			case entry.Captain:
+				want = want + float64(points)*fantasyCaptainMultiplier
+			case entry.ViceCaptain:
+				want = want + float64(points)*fantasyViceMultiplier
+			default:
+				want = want + float64(points)
+			}
+		}
+		entries, err := getFantasyEntries(db, matchid, m.MatchDate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 1 || entries[0].MatchID != matchid || entries[0].Points != want {
+			t.Errorf("match %d entries %+v, want one scoring %.1f", matchid, entries, want)
+		}
+	}
+}
+
+// A pick that is in no imported scorecard, e.g. a misspelt name, is refused
+// rather than priced as a new player who scores nothing.
+func TestFantasyUnknownPlayerRefused(t *testing.T) {
+	db := openTestDB(t)
+	importTestScorecards(t, db, "scorecard.csv", "Mar3-20.csv")
+
+	players, err := getPlayers(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	players = players[:fantasyTeamSize]
+	players[10] = "Jai Vv"
+	entry := fantasyEntry{Member: "Ravi", MatchID: 2, Players: players, Captain: players[0], ViceCaptain: players[1]}
+	if _, err := saveFantasyEntry(db, entry); !errors.Is(err, errInvalid) {
+		t.Errorf("entry picking Jai Vv : %v, want it refused", err)
+	}
+	entries, err := getFantasyEntries(db, 2, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("refused entry was saved : %+v", entries)
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// Form window and how far the recent half of the window has to move from
+// the earlier half, in average points, to count as a trend.
+const (
+	formDefaultMatches = 5
+	formTrendPoints    = 5.0
+)
+
+const sparklineTicks = "▁▂▃▄▅▆▇█"
+
+type formMatch struct {
+	MatchID   int    `json:"matchid"`
+	MatchDate string `json:"matchDate"`
+	Points    int    `json:"points"`
+	Runs      int    `json:"runs"`
+	Wickets   int    `json:"wickets"`
+}
+
+type playerForm struct {
+	Player    string      `json:"player"`
+	Matches   int         `json:"matches"`
+	Average   float64     `json:"average"`
+	Runs      int         `json:"runs"`
+	Wickets   int         `json:"wickets"`
+	Trend     string      `json:"trend"`
+	Sparkline string      `json:"sparkline"`
+	Recent    []formMatch `json:"recent"`
+}
+
+// getForm works out every player's form over their last n matches, oldest
+// match first in Recent, best average first.
+func getForm(db *sql.DB, n int) ([]playerForm, error) {
+
+	formSQL := `select p.matchid, p.MatchDate, TRIM(p.Player), p."Total Points", COALESCE(b.runs, 0), COALESCE(w.wickets, 0)
+		from TotalMatchPoints p
+		LEFT JOIN batsmen b ON b.matchid = p.matchid AND b.battername = p.Player
+		LEFT JOIN bowlers w ON w.matchid = p.matchid AND w.bowlerName = p.Player
+		order by p.matchid`
+	matches := make(map[string][]formMatch)
+	err := queryRows(db, formSQL, nil, func(row *sql.Rows) error {
+		var player string
+		m := formMatch{}
+		err := row.Scan(&m.MatchID, &m.MatchDate, &player, &m.Points, &m.Runs, &m.Wickets)
+		matches[player] = append(matches[player], m)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	form := make([]playerForm, 0)
+	for player, played := range matches {
+		sort.SliceStable(played, func(a, b int) bool {
+			playedA, _ := time.Parse("01/02/2006", played[a].MatchDate)
+			playedB, _ := time.Parse("01/02/2006", played[b].MatchDate)
+			return playedA.Before(playedB)
+		})
+		if len(played) > n {
+			played = played[len(played)-n:]
+		}
+
+		f := playerForm{Player: player, Matches: len(played), Recent: played}
+		points := make([]int, 0)
+		total := 0
+		for _, m := range played {
+			total = total + m.Points
+			f.Runs = f.Runs + m.Runs
+			f.Wickets = f.Wickets + m.Wickets
+			points = append(points, m.Points)
+		}
+		f.Average = float64(total) / float64(len(played))
+		f.Trend = formTrend(points)
+		f.Sparkline = sparkline(points)
+		form = append(form, f)
+	}
+	sort.Slice(form, func(a, b int) bool {
+		if form[a].Average != form[b].Average {
+			return form[a].Average > form[b].Average
+		}
+		return form[a].Player < form[b].Player
+	})
+	return form, nil
+}
+
+// formTrend compares the average of the later half of the matches with the
+// earlier half: "rising", "falling" or "steady".
+func formTrend(points []int) string {
+	if len(points) < 2 {
+		return "steady"
+	}
+	half := len(points) / 2
+	average := func(p []int) float64 {
+		total := 0
+		for _, v := range p {
+			total = total + v
+		}
+		return float64(total) / float64(len(p))
+	}
+	change := average(points[len(points)-half:]) - average(points[:half])
+	switch {
+	case change >= formTrendPoints:
+		return "rising"
+	case change <= -formTrendPoints:
+		return "falling"
+	}
+	return "steady"
+}
+
+// sparkline draws the points as one bar per match, scaled between the
+// lowest and highest value.
+func sparkline(points []int) string {
+	if len(points) == 0 {
+		return ""
+	}
+	ticks := []rune(sparklineTicks)
+	low, high := points[0], points[0]
+	for _, v := range points {
+		low = min(low, v)
+		high = max(high, v)
+	}
+	var line strings.Builder
+	for _, v := range points {
+		tick := len(ticks) / 2
+		if high > low {
+			tick = (v - low) * (len(ticks) - 1) / (high - low)
+		}
+		line.WriteRune(ticks[tick])
+	}
+	return line.String()
+}
+
+func renderForm(form []playerForm, n int) {
+	arrows := map[string]string{"rising": "↑ rising", "falling": "↓ falling", "steady": "→ steady"}
+
+	t := table.NewWriter()
+	t.AppendHeader(table.Row{"S.No", "Player", "Matches", "Avg Points", "Runs", "Wickets", "Trend", "Points"})
+	for i, f := range form {
+		t.AppendRow(table.Row{i + 1, f.Player, f.Matches, fmt.Sprintf("%.1f", f.Average), f.Runs, f.Wickets, arrows[f.Trend], f.Sparkline})
+	}
+	fmt.Println("------------------------------------------")
+	fmt.Printf("Form over the last %d matches\n", n)
+	fmt.Println("------------------------------------------")
+	fmt.Println(t.Render())
+}
+
+func renderFormJSON(form []playerForm) {
+	out, err := json.MarshalIndent(form, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(string(out))
+}
@@ -0,0 +1,16 @@
+module readcsv
+
+go 1.22
+
+require (
+	github.com/jedib0t/go-pretty/v6 v6.8.3
+	github.com/lib/pq v1.9.0
+	github.com/mattn/go-sqlite3 v1.14.52
+)
+
+require (
+	github.com/mattn/go-runewidth v0.0.16 // indirect
+	github.com/rivo/uniseg v0.4.7 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+	golang.org/x/text v0.22.0 // indirect
+)
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+type headToHeadRecord struct {
+	Opponent       string             `json:"opponent"`
+	Matches        int                `json:"matches"`
+	Won            int                `json:"won"`
+	Lost           int                `json:"lost"`
+	Tied           int                `json:"tied"`
+	NoResult       int                `json:"noResult"`
+	AverageFor     float64            `json:"averageScoreFor"`
+	AverageAgainst float64            `json:"averageScoreAgainst"`
+	FantasyPoints  int                `json:"fantasyPoints"`
+	TopPerformers  []leaderboardEntry `json:"topPerformers"`
+	innings        int
+	runsFor        int
+	runsAgainst    int
+}
+
+// getHeadToHead builds Phoenix's record against every opponent, or one
+// opponent when opponentName is not empty.
+func getHeadToHead(db *sql.DB, opponentName string) ([]headToHeadRecord, error) {
+
+	matches, err := getMatches(db)
+	if err != nil {
+		return nil, err
+	}
+	records := make(map[string]*headToHeadRecord)
+	for _, m := range matches {
+		oppo := m.Team1
+		if m.Team1 == "Phoenix" {
+			oppo = m.Team2
+		}
+		if opponentName != "" && !strings.EqualFold(oppo, strings.TrimSpace(opponentName)) {
+			continue
+		}
+		if _, ok := records[oppo]; !ok {
+			records[oppo] = &headToHeadRecord{Opponent: oppo}
+		}
+		r := records[oppo]
+		r.Matches = r.Matches + 1
+		switch matchOutcome(m, "Phoenix") {
+		case "won":
+			r.Won = r.Won + 1
+		case "lost":
+			r.Lost = r.Lost + 1
+		case "tied":
+			r.Tied = r.Tied + 1
+		default:
+			r.NoResult = r.NoResult + 1
+		}
+
+		totals, err := getInningsTotals(db, m.MatchID)
+		if err != nil {
+			return nil, err
+		}
+		if len(totals) == 2 {
+			r.innings = r.innings + 1
+			for _, total := range totals {
+				if total.Team == "Phoenix" {
+					r.runsFor = r.runsFor + total.Runs
+				} else {
+					r.runsAgainst = r.runsAgainst + total.Runs
+				}
+			}
+		}
+	}
+
+	headToHead := make([]headToHeadRecord, 0)
+	for _, r := range records {
+		if r.innings > 0 {
+			r.AverageFor = float64(r.runsFor) / float64(r.innings)
+			r.AverageAgainst = float64(r.runsAgainst) / float64(r.innings)
+		}
+		if r.TopPerformers, err = getOpponentLeaders(db, r.Opponent); err != nil {
+			return nil, err
+		}
+		for _, p := range r.TopPerformers {
+			r.FantasyPoints = r.FantasyPoints + p.Points
+		}
+		if len(r.TopPerformers) > 3 {
+			r.TopPerformers = r.TopPerformers[:3]
+		}
+		headToHead = append(headToHead, *r)
+	}
+	sort.Slice(headToHead, func(a, b int) bool {
+		if headToHead[a].Matches != headToHead[b].Matches {
+			return headToHead[a].Matches > headToHead[b].Matches
+		}
+		return headToHead[a].Opponent < headToHead[b].Opponent
+	})
+	return headToHead, nil
+}
+
+// getOpponentLeaders totals each player's fantasy points against one opponent.
+func getOpponentLeaders(db *sql.DB, opponentName string) ([]leaderboardEntry, error) {
+
+	leadersSQL := `select Player, count(*), sum("Total Points") from TotalMatchPoints
+		where Opponent = ? group by Player order by sum("Total Points") DESC, Player`
+	row, err := db.Query(leadersSQL, opponentName)
+	if err != nil {
+		return nil, err
+	}
+	return scanLeaderboard(row)
+}
+
+func renderHeadToHead(records []headToHeadRecord) {
+
+	t := table.NewWriter()
+	t.AppendHeader(table.Row{"Opponent", "M", "W", "L", "T", "NR", "Avg For", "Avg Against", "Fantasy Points", "Top Performers"})
+	for _, r := range records {
+		top := make([]string, 0)
+		for _, p := range r.TopPerformers {
+			top = append(top, fmt.Sprintf("%s (%d)", p.Player, p.Points))
+		}
+		t.AppendRow(table.Row{r.Opponent, r.Matches, r.Won, r.Lost, r.Tied, r.NoResult,
+			fmt.Sprintf("%.1f", r.AverageFor), fmt.Sprintf("%.1f", r.AverageAgainst), r.FantasyPoints, strings.Join(top, ", ")})
+	}
+	fmt.Println("------------------------------------------")
+	fmt.Println("Head to Head")
+	fmt.Println("------------------------------------------")
+	fmt.Println(t.Render())
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"database/sql"
+)
+
+type leaderboardEntry struct {
+	Player  string  `json:"player"`
+	Matches int     `json:"matches"`
+	Points  int     `json:"points"`
+	Average float64 `json:"average"`
+}
+
+type playerMatchPoints struct {
+	MatchID   int    `json:"matchid"`
+	MatchDate string `json:"matchDate"`
+	Opponent  string `json:"opponent"`
+	Points    int    `json:"points"`
+}
+
+// getLeaderboard totals every player's points across the matches of a
+// series, e.g. "Spring 2022", or across all matches when series is empty.
+func getLeaderboard(db *sql.DB, series string) ([]leaderboardEntry, error) {
+
+	leaderboardSQL := `select p.Player, count(*), sum(p."Total Points")
+		from TotalMatchPoints p JOIN "match" m ON p.matchid = m.matchid
+		where (? = '' OR m.series = ?)
+		group by p.Player order by sum(p."Total Points") DESC, p.Player`
+
+	row, err := db.Query(leaderboardSQL, series, series)
+	if err != nil {
+		return nil, err
+	}
+	return scanLeaderboard(row)
+}
+
+// scanLeaderboard reads rows of player, matches and points.
+func scanLeaderboard(row *sql.Rows) ([]leaderboardEntry, error) {
+	defer row.Close()
+
+	leaderboard := make([]leaderboardEntry, 0)
+	for row.Next() {
+		e := leaderboardEntry{}
+		if err := row.Scan(&e.Player, &e.Matches, &e.Points); err != nil {
+			return nil, err
+		}
+		if e.Matches > 0 {
+			e.Average = float64(e.Points) / float64(e.Matches)
+		}
+		leaderboard = append(leaderboard, e)
+	}
+	return leaderboard, row.Err()
+}
+
+func getPlayers(db *sql.DB) ([]string, error) {
+
+	row, err := db.Query("select distinct TRIM(battername) from batsmen order by TRIM(battername)")
+	if err != nil {
+		return nil, err
+	}
+	return scanPlayers(row)
+}
+
+func scanPlayers(row *sql.Rows) ([]string, error) {
+	defer row.Close()
+
+	players := make([]string, 0)
+	for row.Next() {
+		var player string
+		if err := row.Scan(&player); err != nil {
+			return nil, err
+		}
+		players = append(players, player)
+	}
+	return players, row.Err()
+}
+
+// getPlayerMatchPoints lists a player's points in every match, oldest first.
+func getPlayerMatchPoints(db *sql.DB, playerName string) ([]playerMatchPoints, error) {
+
+	pointsSQL := `select matchid, MatchDate, Opponent, "Total Points" from TotalMatchPoints where TRIM(Player) = TRIM(?) order by matchid`
+	row, err := db.Query(pointsSQL, playerName)
+	if err != nil {
+		return nil, err
+	}
+	return scanPlayerMatchPoints(row)
+}
+
+// scanPlayerMatchPoints reads rows of matchid, match date, opponent and points.
+func scanPlayerMatchPoints(row *sql.Rows) ([]playerMatchPoints, error) {
+	defer row.Close()
+
+	points := make([]playerMatchPoints, 0)
+	for row.Next() {
+		p := playerMatchPoints{}
+		if err := row.Scan(&p.MatchID, &p.MatchDate, &p.Opponent, &p.Points); err != nil {
+			return nil, err
+		}
+		points = append(points, p)
+	}
+	return points, row.Err()
+}
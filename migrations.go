@@ -0,0 +1,285 @@
+package main
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// migrationFiles are the schema changes, numbered 0001_<name>.sql onwards
+// and applied in order. A released migration is never edited, a schema
+// change is a new file.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+type migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// loadMigrations reads the numbered migrations in dir of files.
+func loadMigrations(files embed.FS, dir string) []migration {
+	names, err := fs.Glob(files, dir+"/*.sql")
+	if err != nil {
+		log.Fatal(err)
+	}
+	migrations := make([]migration, 0)
+	for _, f := range names {
+		name := strings.TrimSuffix(path.Base(f), ".sql")
+		number, _, _ := strings.Cut(name, "_")
+		version, err := strconv.Atoi(number)
+		if err != nil {
+			log.Fatalln("migration " + f + " is not named <version>_<name>.sql")
+		}
+		content, err := files.ReadFile(f)
+		if err != nil {
+			log.Fatal(err)
+		}
+		migrations = append(migrations, migration{Version: version, Name: name, SQL: string(content)})
+	}
+	sort.Slice(migrations, func(a, b int) bool { return migrations[a].Version < migrations[b].Version })
+	return migrations
+}
+
+func hasTable(db *sql.DB, name string) bool {
+	var count int
+	err := db.QueryRow(`select count(*) from sqlite_master where type = 'table' AND name = ?`, name).Scan(&count)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return count > 0
+}
+
+// schemaVersion is the last migration applied, 0 for a database from
+// before migrations were kept.
+func schemaVersion(db *sql.DB) int {
+	if !hasTable(db, "schema_version") {
+		return 0
+	}
+	var version int
+	err := db.QueryRow(`select coalesce(max(version), 0) from schema_version`).Scan(&version)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return version
+}
+
+func pendingMigrations(db *sql.DB) []migration {
+	current := schemaVersion(db)
+	pending := make([]migration, 0)
+	for _, m := range loadMigrations(migrationFiles, "migrations") {
+		if m.Version > current {
+			pending = append(pending, m)
+		}
+	}
+	return pending
+}
+
+// migrationReports run in a migration's transaction before it, to log what
+// the migration is about to change that the SQL alone would do silently.
+var migrationReports = map[int]func(tx *sql.Tx) ([]string, error){
+	7: keysAndIndexesDrops,
+}
+
+// keyedTables are the tables migration 0007 rebuilds: the column keying
+// each row to its parent table and the name column that has to be set and,
+// with the key, unique. fielders and adjustments only lose orphan rows.
+var keyedTables = []struct{ table, key, parent, name string }{
+	{"batsmen", "matchid", `"match"`, "battername"},
+	{"bowlers", "matchid", `"match"`, "bowlerName"},
+	{"fielders", "matchid", `"match"`, ""},
+	{"innings", "matchid", `"match"`, "team"},
+	{"adjustments", "matchid", `"match"`, ""},
+	{"fantasy_picks", "entryid", "fantasy_entries", "player"},
+}
+
+// keysAndIndexesDrops counts the rows migration 0007 drops: rows of matches
+// or fantasy entries that no longer exist, rows without a name and all but
+// the first of duplicate rows, links of fantasy entries to matches that no
+// longer exist, and all but the last role set for a player.
+func keysAndIndexesDrops(tx *sql.Tx) ([]string, error) {
+	drops := make([]string, 0)
+	report := func(query string, what string) error {
+		total := 0
+		keys := make([]string, 0)
+		row, err := tx.Query(query)
+		if err != nil {
+			return err
+		}
+		defer row.Close()
+		for row.Next() {
+			var key string
+			var count int
+			if err := row.Scan(&key, &count); err != nil {
+				return err
+			}
+			total = total + count
+			keys = append(keys, key)
+		}
+		if err := row.Err(); err != nil {
+			return err
+		}
+		if total > 0 {
+			drops = append(drops, fmt.Sprintf(what, total)+strings.Join(keys, ", "))
+		}
+		return nil
+	}
+
+	for _, t := range keyedTables {
+		kept := fmt.Sprintf(`%s IN (select %s from %s)`, t.key, t.key, t.parent)
+		orphans := fmt.Sprintf(`select COALESCE(CAST(%[2]s AS TEXT), 'none'), count(*) from %[1]s
+			where NOT (%[3]s) OR %[2]s IS NULL group by %[2]s order by %[2]s`, t.table, t.key, kept)
+		if err := report(orphans, "%d "+t.table+" rows of deleted "+strings.Trim(t.parent, `"`)+" rows, "+t.key+" "); err != nil {
+			return nil, err
+		}
+		if t.name == "" {
+			continue
+		}
+		unnamed := fmt.Sprintf(`select CAST(%[2]s AS TEXT), count(*) from %[1]s
+			where %[3]s AND %[4]s IS NULL group by %[2]s order by %[2]s`, t.table, t.key, kept, t.name)
+		if err := report(unnamed, "%d "+t.table+" rows without a "+t.name+", "+t.key+" "); err != nil {
+			return nil, err
+		}
+		duplicates := fmt.Sprintf(`select CAST(%[2]s AS TEXT), sum(n - 1) from (select %[2]s, count(*) n from %[1]s
+			where %[3]s AND %[4]s IS NOT NULL group by %[2]s, %[4]s having count(*) > 1) group by %[2]s order by %[2]s`,
+			t.table, t.key, kept, t.name)
+		if err := report(duplicates, "%d duplicate "+t.table+" rows, the first is kept, "+t.key+" "); err != nil {
+			return nil, err
+		}
+	}
+	unlinked := `select CAST(matchid AS TEXT), count(*) from fantasy_entries
+		where matchid IS NOT NULL AND matchid NOT IN (select matchid from "match") group by matchid order by matchid`
+	if err := report(unlinked, "%d fantasy_entries links to deleted match rows, the entry is kept, matchid "); err != nil {
+		return nil, err
+	}
+	roles := `select TRIM(player), count(*) - 1 from player_roles group by TRIM(player) having count(*) > 1 order by TRIM(player)`
+	if err := report(roles, "%d older player_roles rows, the last role set is kept, for "); err != nil {
+		return nil, err
+	}
+	return drops, nil
+}
+
+// applyMigrations runs each migration in its own transaction together with
+// its schema_version row, so a failed migration leaves the database at the
+// previous version.
+func applyMigrations(db *sql.DB, pending []migration) {
+	err := execQuery(db, `CREATE TABLE IF NOT EXISTS schema_version (
+		"version" INTEGER NOT NULL PRIMARY KEY,
+		"name" TEXT,
+		"appliedAt" TEXT
+	  );`, "Creating Schema Version Table")
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+
+	for _, m := range pending {
+		tx, err := db.Begin()
+		if err != nil {
+			log.Fatalln(err.Error())
+		}
+		if report, ok := migrationReports[m.Version]; ok {
+			drops, err := report(tx)
+			if err != nil {
+				tx.Rollback()
+				log.Fatalln("Migration " + m.Name + " failed : " + err.Error())
+			}
+			for _, drop := range drops {
+				log.Println("Migration " + m.Name + " drops " + drop)
+			}
+		}
+		if _, err = tx.Exec(m.SQL); err != nil {
+			tx.Rollback()
+			log.Fatalln("Migration " + m.Name + " failed : " + err.Error())
+		}
+		_, err = tx.Exec(`INSERT INTO schema_version (version,name,appliedAt) VALUES (?,?,?)`, m.Version, m.Name, time.Now().Format("2006-01-02 15:04:05"))
+		if err != nil {
+			tx.Rollback()
+			log.Fatalln(err.Error())
+		}
+		if err = tx.Commit(); err != nil {
+			log.Fatalln(err.Error())
+		}
+		log.Println("Applied migration " + m.Name)
+	}
+}
+
+// backupDatabase writes a consistent copy of the database next to it and
+// returns the copy's file name.
+func backupDatabase(db *sql.DB) string {
+	backup := fmt.Sprintf("phoenixPoints-v%d-%s.db", schemaVersion(db), time.Now().Format("20060102-150405"))
+	if _, err := db.Exec(`VACUUM INTO ?`, backup); err != nil {
+		log.Fatalln("Backup failed, database not migrated : " + err.Error())
+	}
+	log.Println("Database backed up to " + backup)
+	return backup
+}
+
+// migrateDatabase backs up the database and applies the pending
+// migrations. The points table is rebuilt afterwards so it has the
+// current scoring columns.
+func migrateDatabase(db *sql.DB) {
+	pending := pendingMigrations(db)
+	if len(pending) == 0 {
+		log.Println("Database is up to date at schema version " + fmt.Sprint(schemaVersion(db)))
+		return
+	}
+	hasMatches := hasTable(db, "match")
+	if hasMatches {
+		backupDatabase(db)
+	}
+	applyMigrations(db, pending)
+
+	var count int
+	if hasMatches {
+		if err := db.QueryRow(`select count(*) from "match"`).Scan(&count); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if count > 0 {
+		if err := calculatePoints(db); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func renderMigrations(db *sql.DB) {
+	applied := make(map[int]string)
+	if hasTable(db, "schema_version") {
+		row, err := db.Query(`select version, appliedAt from schema_version`)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer row.Close()
+		for row.Next() {
+			var version int
+			var appliedAt string
+			row.Scan(&version, &appliedAt)
+			applied[version] = appliedAt
+		}
+	}
+
+	t := table.NewWriter()
+	t.AppendHeader(table.Row{"Version", "Migration", "Applied At"})
+	for _, m := range loadMigrations(migrationFiles, "migrations") {
+		appliedAt, ok := applied[m.Version]
+		if !ok {
+			appliedAt = "pending"
+		}
+		t.AppendRow(table.Row{m.Version, m.Name, appliedAt})
+	}
+	fmt.Println("------------------------------------------")
+	fmt.Println("Schema Version : " + fmt.Sprint(schemaVersion(db)))
+	fmt.Println("------------------------------------------")
+	fmt.Println(t.Render())
+}
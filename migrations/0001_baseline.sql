@@ -0,0 +1,43 @@
+-- The tables the first release created.
+CREATE TABLE IF NOT EXISTS match (
+	"matchid" integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+	"series" TEXT,
+	"stage" TEXT,
+	"division" TEXT,
+	"matchDate" TEXT,
+	"Team1" TEXT,
+	"Team2" TEXT,
+	"Result" TEXT
+);
+
+CREATE TABLE IF NOT EXISTS bowlers (
+	"matchid" INTEGER,
+	"bowlerName" TEXT,
+	"overs" TEXT,
+	"Maidens" INTEGER DEFAULT 0,
+	"RunsGiven" INTEGER DEFAULT 0,
+	"wickets" INTEGER DEFAULT 0,
+	"Wides" INTEGER DEFAULT 0,
+	"NoBalls" INTEGER DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS batsmen (
+	"matchid" INTEGER,
+	"battername" TEXT,
+	"runs" INTEGER DEFAULT 0,
+	"balls" INTEGER DEFAULT 0,
+	"fours" INTEGER DEFAULT 0,
+	"sixers" INTEGER DEFAULT 0,
+	"Notout" INTEGER DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS fielders (
+	"matchid" INTEGER,
+	"Batsman" TEXT,
+	"wicketType" TEXT,
+	"fieldername" TEXT,
+	"bowlername" TEXT,
+	"bowled" INTEGER DEFAULT 0,
+	"catches" INTEGER DEFAULT 0,
+	"runouts" INTEGER DEFAULT 0
+);
@@ -0,0 +1,9 @@
+-- Manual adjustments (1-run overs, dropped catches) kept per match.
+CREATE TABLE IF NOT EXISTS adjustments (
+	"matchid" INTEGER,
+	"player" TEXT,
+	"field" TEXT,
+	"value" INTEGER DEFAULT 0,
+	"enteredBy" TEXT,
+	"enteredAt" TEXT
+);
@@ -0,0 +1,8 @@
+-- Innings totals for standings and net run rate.
+CREATE TABLE IF NOT EXISTS innings (
+	"matchid" INTEGER,
+	"team" TEXT,
+	"runs" INTEGER DEFAULT 0,
+	"wickets" INTEGER DEFAULT 0,
+	"overs" TEXT
+);
@@ -0,0 +1,15 @@
+-- Fantasy league entries and their picks.
+CREATE TABLE IF NOT EXISTS fantasy_entries (
+	"entryid" integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+	"member" TEXT,
+	"matchid" INTEGER,
+	"matchDate" TEXT,
+	"captain" TEXT,
+	"viceCaptain" TEXT,
+	"enteredAt" TEXT
+);
+
+CREATE TABLE IF NOT EXISTS fantasy_picks (
+	"entryid" INTEGER,
+	"player" TEXT
+);
@@ -0,0 +1,7 @@
+-- Player roles set by hand, overriding the inferred ones.
+CREATE TABLE IF NOT EXISTS player_roles (
+	"player" TEXT,
+	"role" TEXT,
+	"enteredBy" TEXT,
+	"enteredAt" TEXT
+);
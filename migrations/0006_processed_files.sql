@@ -0,0 +1,9 @@
+-- Scorecards seen by watch mode.
+CREATE TABLE IF NOT EXISTS processed_files (
+	"path" TEXT,
+	"size" INTEGER,
+	"modTime" TEXT,
+	"status" TEXT,
+	"matchid" INTEGER,
+	"processedAt" TEXT
+);
@@ -0,0 +1,132 @@
+-- Primary keys, foreign keys to match and indexes. SQLite cannot add
+-- constraints to a table, so the stats tables are rebuilt: rows of matches
+-- that no longer exist are dropped, and of duplicate batter or bowler rows
+-- in a match the first is kept.
+
+CREATE TABLE batsmen_new (
+	"batterid" integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+	"matchid" INTEGER NOT NULL REFERENCES "match" (matchid) ON DELETE CASCADE,
+	"innings" INTEGER NOT NULL DEFAULT 1,
+	"battername" TEXT NOT NULL,
+	"runs" INTEGER DEFAULT 0,
+	"balls" INTEGER DEFAULT 0,
+	"fours" INTEGER DEFAULT 0,
+	"sixers" INTEGER DEFAULT 0,
+	"Notout" INTEGER DEFAULT 0,
+	UNIQUE (matchid, innings, battername)
+);
+INSERT OR IGNORE INTO batsmen_new (matchid,battername,runs,balls,fours,sixers,Notout)
+	SELECT matchid,battername,runs,balls,fours,sixers,Notout FROM batsmen
+	WHERE matchid IN (SELECT matchid FROM "match") AND battername IS NOT NULL ORDER BY rowid;
+DROP TABLE batsmen;
+ALTER TABLE batsmen_new RENAME TO batsmen;
+
+CREATE TABLE bowlers_new (
+	"bowlerid" integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+	"matchid" INTEGER NOT NULL REFERENCES "match" (matchid) ON DELETE CASCADE,
+	"innings" INTEGER NOT NULL DEFAULT 1,
+	"bowlerName" TEXT NOT NULL,
+	"overs" TEXT,
+	"Maidens" INTEGER DEFAULT 0,
+	"RunsGiven" INTEGER DEFAULT 0,
+	"wickets" INTEGER DEFAULT 0,
+	"Wides" INTEGER DEFAULT 0,
+	"NoBalls" INTEGER DEFAULT 0,
+	UNIQUE (matchid, innings, bowlerName)
+);
+INSERT OR IGNORE INTO bowlers_new (matchid,bowlerName,overs,Maidens,RunsGiven,wickets,Wides,NoBalls)
+	SELECT matchid,bowlerName,overs,Maidens,RunsGiven,wickets,Wides,NoBalls FROM bowlers
+	WHERE matchid IN (SELECT matchid FROM "match") AND bowlerName IS NOT NULL ORDER BY rowid;
+DROP TABLE bowlers;
+ALTER TABLE bowlers_new RENAME TO bowlers;
+
+-- A fielder can be credited more than once in an innings (two catches, a
+-- catch and a run out), so fielders has no unique constraint.
+CREATE TABLE fielders_new (
+	"fieldingid" integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+	"matchid" INTEGER NOT NULL REFERENCES "match" (matchid) ON DELETE CASCADE,
+	"innings" INTEGER NOT NULL DEFAULT 1,
+	"Batsman" TEXT,
+	"wicketType" TEXT,
+	"fieldername" TEXT,
+	"bowlername" TEXT,
+	"bowled" INTEGER DEFAULT 0,
+	"catches" INTEGER DEFAULT 0,
+	"runouts" INTEGER DEFAULT 0
+);
+INSERT INTO fielders_new (matchid,Batsman,wicketType,fieldername,bowlername,bowled,catches,runouts)
+	SELECT matchid,Batsman,wicketType,fieldername,bowlername,bowled,catches,runouts FROM fielders
+	WHERE matchid IN (SELECT matchid FROM "match") ORDER BY rowid;
+DROP TABLE fielders;
+ALTER TABLE fielders_new RENAME TO fielders;
+
+CREATE TABLE innings_new (
+	"matchid" INTEGER NOT NULL REFERENCES "match" (matchid) ON DELETE CASCADE,
+	"team" TEXT NOT NULL,
+	"runs" INTEGER DEFAULT 0,
+	"wickets" INTEGER DEFAULT 0,
+	"overs" TEXT,
+	PRIMARY KEY (matchid, team)
+);
+INSERT OR IGNORE INTO innings_new (matchid,team,runs,wickets,overs)
+	SELECT matchid,team,runs,wickets,overs FROM innings
+	WHERE matchid IN (SELECT matchid FROM "match") AND team IS NOT NULL ORDER BY rowid;
+DROP TABLE innings;
+ALTER TABLE innings_new RENAME TO innings;
+
+CREATE TABLE adjustments_new (
+	"adjustmentid" integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+	"matchid" INTEGER NOT NULL REFERENCES "match" (matchid) ON DELETE CASCADE,
+	"player" TEXT,
+	"field" TEXT,
+	"value" INTEGER DEFAULT 0,
+	"enteredBy" TEXT,
+	"enteredAt" TEXT
+);
+INSERT INTO adjustments_new (matchid,player,field,value,enteredBy,enteredAt)
+	SELECT matchid,player,field,value,enteredBy,enteredAt FROM adjustments
+	WHERE matchid IN (SELECT matchid FROM "match") ORDER BY rowid;
+DROP TABLE adjustments;
+ALTER TABLE adjustments_new RENAME TO adjustments;
+
+CREATE TABLE fantasy_entries_new (
+	"entryid" integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+	"member" TEXT,
+	"matchid" INTEGER REFERENCES "match" (matchid) ON DELETE SET NULL,
+	"matchDate" TEXT,
+	"captain" TEXT,
+	"viceCaptain" TEXT,
+	"enteredAt" TEXT
+);
+INSERT INTO fantasy_entries_new (entryid,member,matchid,matchDate,captain,viceCaptain,enteredAt)
+	SELECT entryid,member,CASE WHEN matchid IN (SELECT matchid FROM "match") THEN matchid END,
+		matchDate,captain,viceCaptain,enteredAt FROM fantasy_entries ORDER BY entryid;
+DROP TABLE fantasy_entries;
+ALTER TABLE fantasy_entries_new RENAME TO fantasy_entries;
+
+CREATE TABLE fantasy_picks_new (
+	"entryid" INTEGER NOT NULL REFERENCES fantasy_entries (entryid) ON DELETE CASCADE,
+	"player" TEXT NOT NULL,
+	PRIMARY KEY (entryid, player)
+);
+INSERT OR IGNORE INTO fantasy_picks_new (entryid,player)
+	SELECT entryid,player FROM fantasy_picks
+	WHERE entryid IN (SELECT entryid FROM fantasy_entries) AND player IS NOT NULL ORDER BY rowid;
+DROP TABLE fantasy_picks;
+ALTER TABLE fantasy_picks_new RENAME TO fantasy_picks;
+
+-- The last role set for a player wins.
+DELETE FROM player_roles WHERE rowid NOT IN (SELECT max(rowid) FROM player_roles GROUP BY TRIM(player));
+CREATE UNIQUE INDEX player_roles_player ON player_roles (player);
+
+-- Leaderboard, profile and import lookups.
+CREATE INDEX match_date_teams ON "match" (matchDate, Team1, Team2);
+CREATE INDEX match_series ON "match" (series);
+CREATE INDEX batsmen_player ON batsmen (battername);
+CREATE INDEX bowlers_player ON bowlers (bowlerName);
+CREATE INDEX fielders_match_fielder ON fielders (matchid, fieldername);
+CREATE INDEX fielders_match_bowler ON fielders (matchid, bowlername);
+CREATE INDEX adjustments_match_player ON adjustments (matchid, player);
+CREATE INDEX fantasy_entries_date ON fantasy_entries (matchDate, member);
+CREATE INDEX fantasy_entries_match ON fantasy_entries (matchid, member);
+CREATE INDEX processed_files_path ON processed_files (path);
@@ -0,0 +1,33 @@
+-- Every manual change: name replacements, adjustments, stat edits, roles,
+-- deleted and re-imported matches. matchid has no foreign key so the log
+-- outlives a deleted match.
+CREATE TABLE audit_log (
+	"auditid" integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+	"kind" TEXT NOT NULL,
+	"matchid" INTEGER,
+	"player" TEXT,
+	"section" TEXT,
+	"statid" INTEGER,
+	"field" TEXT,
+	"oldValue" TEXT,
+	"newValue" TEXT,
+	"reason" TEXT,
+	"changedBy" TEXT,
+	"changedAt" TEXT,
+	"revertedBy" INTEGER
+);
+CREATE INDEX audit_log_match_player ON audit_log (matchid, player);
+
+-- Adjustments and roles entered before the log was kept. An adjustment's
+-- old value is the one entered before it; entries that changed nothing
+-- (the 0s of the import prompts) are left out.
+INSERT INTO audit_log (kind,matchid,player,field,oldValue,newValue,reason,changedBy,changedAt)
+	SELECT 'adjustment', matchid, player, field, old, value, 'entered before the audit log', enteredBy, enteredAt
+	FROM (SELECT a.*, coalesce((SELECT a2.value FROM adjustments a2
+			WHERE a2.matchid = a.matchid AND a2.player = a.player AND a2.field = a.field AND a2.rowid < a.rowid
+			ORDER BY a2.rowid DESC LIMIT 1), 0) AS old
+		FROM adjustments a)
+	WHERE value <> old ORDER BY adjustmentid;
+INSERT INTO audit_log (kind,matchid,player,field,oldValue,newValue,reason,changedBy,changedAt)
+	SELECT 'role', 0, player, 'role', 'auto', role, 'entered before the audit log', enteredBy, enteredAt
+	FROM player_roles ORDER BY rowid;
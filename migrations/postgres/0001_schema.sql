@@ -0,0 +1,89 @@
+-- The matches, stats, adjustments and roles of the SQLite schema at
+-- version 8. Names are left unquoted, so PostgreSQL folds them to lower
+-- case and the same mixed case names work in queries.
+CREATE TABLE match (
+	matchid SERIAL PRIMARY KEY,
+	series TEXT,
+	stage TEXT,
+	division TEXT,
+	matchDate TEXT,
+	Team1 TEXT,
+	Team2 TEXT,
+	Result TEXT
+);
+
+CREATE TABLE batsmen (
+	batterid SERIAL PRIMARY KEY,
+	matchid INTEGER NOT NULL REFERENCES match (matchid) ON DELETE CASCADE,
+	innings INTEGER NOT NULL DEFAULT 1,
+	battername TEXT NOT NULL,
+	runs INTEGER DEFAULT 0,
+	balls INTEGER DEFAULT 0,
+	fours INTEGER DEFAULT 0,
+	sixers INTEGER DEFAULT 0,
+	Notout INTEGER DEFAULT 0,
+	UNIQUE (matchid, innings, battername)
+);
+
+CREATE TABLE bowlers (
+	bowlerid SERIAL PRIMARY KEY,
+	matchid INTEGER NOT NULL REFERENCES match (matchid) ON DELETE CASCADE,
+	innings INTEGER NOT NULL DEFAULT 1,
+	bowlerName TEXT NOT NULL,
+	overs TEXT,
+	Maidens INTEGER DEFAULT 0,
+	RunsGiven INTEGER DEFAULT 0,
+	wickets INTEGER DEFAULT 0,
+	Wides INTEGER DEFAULT 0,
+	NoBalls INTEGER DEFAULT 0,
+	UNIQUE (matchid, innings, bowlerName)
+);
+
+CREATE TABLE fielders (
+	fieldingid SERIAL PRIMARY KEY,
+	matchid INTEGER NOT NULL REFERENCES match (matchid) ON DELETE CASCADE,
+	innings INTEGER NOT NULL DEFAULT 1,
+	Batsman TEXT,
+	wicketType TEXT,
+	fieldername TEXT,
+	bowlername TEXT,
+	bowled INTEGER DEFAULT 0,
+	catches INTEGER DEFAULT 0,
+	runouts INTEGER DEFAULT 0
+);
+
+-- inningsid keeps the order the innings were batted in.
+CREATE TABLE innings (
+	inningsid SERIAL NOT NULL,
+	matchid INTEGER NOT NULL REFERENCES match (matchid) ON DELETE CASCADE,
+	team TEXT NOT NULL,
+	runs INTEGER DEFAULT 0,
+	wickets INTEGER DEFAULT 0,
+	overs TEXT,
+	PRIMARY KEY (matchid, team)
+);
+
+CREATE TABLE adjustments (
+	adjustmentid SERIAL PRIMARY KEY,
+	matchid INTEGER NOT NULL REFERENCES match (matchid) ON DELETE CASCADE,
+	player TEXT,
+	field TEXT,
+	value INTEGER DEFAULT 0,
+	enteredBy TEXT,
+	enteredAt TEXT
+);
+
+CREATE TABLE player_roles (
+	player TEXT PRIMARY KEY,
+	role TEXT,
+	enteredBy TEXT,
+	enteredAt TEXT
+);
+
+CREATE INDEX match_date_teams ON match (matchDate, Team1, Team2);
+CREATE INDEX match_series ON match (series);
+CREATE INDEX batsmen_player ON batsmen (battername);
+CREATE INDEX bowlers_player ON bowlers (bowlerName);
+CREATE INDEX fielders_match_fielder ON fielders (matchid, fieldername);
+CREATE INDEX fielders_match_bowler ON fielders (matchid, bowlername);
+CREATE INDEX adjustments_match_player ON adjustments (matchid, player);
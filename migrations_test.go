@@ -0,0 +1,74 @@
+package main
+
+import (
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// Migration 0007 reports every row it drops before dropping it.
+func TestKeysAndIndexesDrops(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "phoenixPoints.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	before, keys := make([]migration, 0), make([]migration, 0)
+	for _, m := range loadMigrations(migrationFiles, "migrations") {
+		if m.Version < 7 {
+			before = append(before, m)
+		} else if m.Version == 7 {
+			keys = append(keys, m)
+		}
+	}
+	applyMigrations(db, before)
+
+	for _, q := range []string{
+		`INSERT INTO "match" (matchid, matchDate, Team1, Team2) VALUES (1, '03/05/2022', 'Phoenix', 'Dublin Warriors')`,
+		`INSERT INTO batsmen (matchid, battername, runs) VALUES (1, 'Jai V', 10), (1, 'Jai V', 12), (1, 'Jai V', 14), (1, NULL, 0), (7, 'Jai V', 3), (9, 'Ram', 1), (NULL, 'Ram', 1)`,
+		`INSERT INTO bowlers (matchid, bowlerName, overs) VALUES (1, 'Vikas Sawkar', '4.0'), (1, 'Vikas Sawkar', '2.0')`,
+		`INSERT INTO fielders (matchid, fieldername, catches) VALUES (1, 'Jai V', 1), (1, 'Jai V', 1), (7, 'Jai V', 1)`,
+		`INSERT INTO fantasy_entries (entryid, member, matchid) VALUES (1, 'Ravi', 7)`,
+		`INSERT INTO fantasy_picks (entryid, player) VALUES (1, 'Jai V'), (1, 'Jai V'), (2, 'Jai V')`,
+		`INSERT INTO player_roles (player, role) VALUES ('Jai V', 'batter'), ('Jai V ', 'bowler'), ('Ram', 'batter')`,
+	} {
+		if _, err := db.Exec(q); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	drops, err := keysAndIndexesDrops(tx)
+	tx.Rollback()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"3 batsmen rows of deleted match rows, matchid none, 7, 9",
+		"1 batsmen rows without a battername, matchid 1",
+		"2 duplicate batsmen rows, the first is kept, matchid 1",
+		"1 duplicate bowlers rows, the first is kept, matchid 1",
+		"1 fielders rows of deleted match rows, matchid 7",
+		"1 fantasy_picks rows of deleted fantasy_entries rows, entryid 2",
+		"1 duplicate fantasy_picks rows, the first is kept, entryid 1",
+		"1 fantasy_entries links to deleted match rows, the entry is kept, matchid 7",
+		"1 older player_roles rows, the last role set is kept, for Jai V",
+	}
+	if !reflect.DeepEqual(drops, want) {
+		t.Errorf("drops\n%q\nwant\n%q", drops, want)
+	}
+
+	applyMigrations(db, keys)
+	var batsmen, fielders, linked int
+	if err := db.QueryRow(`select (select count(*) from batsmen), (select count(*) from fielders),
+		(select count(matchid) from fantasy_entries)`).Scan(&batsmen, &fielders, &linked); err != nil {
+		t.Fatal(err)
+	}
+	if batsmen != 1 || fielders != 2 || linked != 0 {
+		t.Errorf("after the migration %d batsmen, %d fielders rows and %d linked fantasy entries, want 1, 2 and 0", batsmen, fielders, linked)
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Rows read from the Phoenix batting, bowling and fielding sections of a
+// scorecard. Figures stay as the text in the csv, the columns' INTEGER
+// affinity stores them as numbers.
+type batterRow struct {
+	Name   string `json:"name"`
+	Runs   string `json:"runs"`
+	Balls  string `json:"balls"`
+	Fours  string `json:"fours"`
+	Sixers string `json:"sixers"`
+	NotOut int    `json:"notOut"`
+}
+
+type bowlerRow struct {
+	Name      string `json:"name"`
+	Overs     string `json:"overs"`
+	Maidens   string `json:"maidens"`
+	RunsGiven string `json:"runsGiven"`
+	Wickets   string `json:"wickets"`
+	Wides     string `json:"wides"`
+	NoBalls   string `json:"noBalls"`
+}
+
+type fielderRow struct {
+	Batsman    string `json:"batsman"`
+	WicketType string `json:"wicketType"`
+	Fielder    string `json:"fielder"`
+	Bowler     string `json:"bowler"`
+	Bowled     int    `json:"bowled"`
+	Catches    int    `json:"catches"`
+	RunOuts    int    `json:"runOuts"`
+}
+
+// parsedScorecard is everything an import stores for one scorecard.
+type parsedScorecard struct {
+	Match    matchDetails   `json:"match"`
+	Batting  []batterRow    `json:"batting"`
+	Bowling  []bowlerRow    `json:"bowling"`
+	Fielding []fielderRow   `json:"fielding"`
+	Innings  []inningsTotal `json:"innings"`
+}
+
+func parseBatting(battingArray []string) []batterRow {
+	batting := make([]batterRow, 0)
+	for i := 0; i < len(battingArray); i++ {
+		battingSplits := strings.Split(battingArray[i], ",")
+		if battingSplits[0] == "BatsMan" {
+			continue
+		}
+		b := batterRow{Name: battingSplits[0], Runs: battingSplits[4], Balls: battingSplits[5], Fours: battingSplits[6], Sixers: battingSplits[7]}
+		ballsfaced, _ := strconv.Atoi(battingSplits[5])
+		if len(battingSplits[1]) == 0 && ballsfaced > 0 {
+			// Nothing on "how Out" but more than 1 ball faced, means they are not out
+			b.NotOut = 1
+		}
+		batting = append(batting, b)
+	}
+	return batting
+}
+
+func parseBowling(bowlingArray []string) []bowlerRow {
+	bowling := make([]bowlerRow, 0)
+	for i := 0; i < len(bowlingArray); i++ {
+		bowlingSplits := strings.Split(bowlingArray[i], ",")
+		if bowlingSplits[0] == "Bowler" {
+			continue
+		}
+		bowling = append(bowling, bowlerRow{Name: bowlingSplits[0], Overs: bowlingSplits[1], Maidens: bowlingSplits[2], RunsGiven: bowlingSplits[3],
+			Wickets: bowlingSplits[4], Wides: bowlingSplits[5], NoBalls: bowlingSplits[6]})
+	}
+	return bowling
+}
+
+// parseFielding reads the dismissals in the opponent's batting section.
+// The scorecard shortens fielder and bowler names, fullName returns the
+// full name of the Phoenix batter the short name starts.
+func parseFielding(fieldingArray []string, fullName func(partialName string) string) []fielderRow {
+	fielding := make([]fielderRow, 0)
+	for i := 0; i < len(fieldingArray); i++ {
+		fieldingSplits := strings.Split(fieldingArray[i], ",")
+		if fieldingSplits[0] == "BatsMan" {
+			continue
+		}
+		batsman := fieldingSplits[0]
+
+		// find the dismissal Type
+		switch fieldingSplits[1] {
+		case "ct":
+			//find if its a caught and Bowled - if bowler == Fielder
+			if fieldingSplits[2] == fieldingSplits[3] {
+				// Its a Caught and Bowled
+				fielder := fullName(fieldingSplits[2])
+				fielding = append(fielding, fielderRow{Batsman: batsman, WicketType: "Caught&Bowled", Fielder: fielder, Bowler: fielder, Catches: 1})
+			} else {
+				// Its a catch
+				fielding = append(fielding, fielderRow{Batsman: batsman, WicketType: "Caught", Fielder: fullName(fieldingSplits[2]), Bowler: fullName(fieldingSplits[3]), Catches: 1})
+			}
+		case "b":
+			fielding = append(fielding, fielderRow{Batsman: batsman, WicketType: "Bowled", Bowler: fullName(fieldingSplits[3]), Bowled: 1})
+		case "ro":
+			// Find if its a Direct Hit - If Filder Name is null , then its a Direct Hit
+			if strings.Trim(fieldingSplits[2], " ") == "" {
+				// Its a Direct Hit
+				fielding = append(fielding, fielderRow{Batsman: batsman, WicketType: "RunOut-DirectHit", Fielder: fullName(fieldingSplits[3]), RunOuts: 1})
+			} else {
+				// Simple Runout , 2 fielders are involved , give runout credit to both
+				fielding = append(fielding, fielderRow{Batsman: batsman, WicketType: "RunOut", Fielder: fullName(fieldingSplits[2]), RunOuts: 1})
+				fielding = append(fielding, fielderRow{Batsman: batsman, WicketType: "RunOut", Fielder: fullName(fieldingSplits[3]), RunOuts: 1})
+			}
+		case "ctw":
+			// Caught Behind
+			fielding = append(fielding, fielderRow{Batsman: batsman, WicketType: "CaughtBehind", Fielder: fullName(fieldingSplits[2]), Bowler: fullName(fieldingSplits[3]), Catches: 1})
+		}
+	}
+	return fielding
+}
+
+// parseScorecard reads a whole scorecard without touching the database or
+// the package variables, so several can be parsed at once. It resolves
+// short fielder names against the scorecard's own batting order, as
+// getFullPlayerName does against the stored batsmen.
+func parseScorecard(scorecard string) (parsedScorecard, error) {
+
+	m, err := readMatchHeader(scorecard)
+	if err != nil {
+		return parsedScorecard{}, err
+	}
+	parsed := parsedScorecard{Match: m}
+	oppo := parsed.Match.Team1
+	if oppo == "Phoenix" {
+		oppo = parsed.Match.Team2
+	}
+
+	batting, err := readSection(scorecard, "Phoenix Batting", "Byes:")
+	if err != nil {
+		return parsed, err
+	}
+	parsed.Batting = parseBatting(batting)
+	bowling, err := readSection(scorecard, "Phoenix Bowling", "Total,")
+	if err != nil {
+		return parsed, err
+	}
+	parsed.Bowling = parseBowling(bowling)
+
+	fullName := func(partialName string) string {
+		for _, b := range parsed.Batting {
+			if strings.HasPrefix(strings.ToLower(b.Name), strings.ToLower(partialName)) {
+				return b.Name
+			}
+		}
+		return ""
+	}
+	fielding, err := readSection(scorecard, oppo+" Batting", "Byes:")
+	if err != nil {
+		return parsed, err
+	}
+	parsed.Fielding = parseFielding(fielding, fullName)
+
+	for _, team := range []string{parsed.Match.Team1, parsed.Match.Team2} {
+		total, err := extractInningsTotal(scorecard, team)
+		if err != nil {
+			return parsed, err
+		}
+		parsed.Innings = append(parsed.Innings, total)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+type battingStats struct {
+	Innings    int     `json:"innings"`
+	Runs       int     `json:"runs"`
+	NotOuts    int     `json:"notOuts"`
+	Balls      int     `json:"balls"`
+	Average    float64 `json:"average"`
+	StrikeRate float64 `json:"strikeRate"`
+	HighScore  int     `json:"-"`
+	HighNotOut bool    `json:"-"`
+	Highest    string  `json:"highScore"`
+	Fours      int     `json:"fours"`
+	Sixers     int     `json:"sixers"`
+	Ducks      int     `json:"ducks"`
+}
+
+type bowlingStats struct {
+	Innings     int     `json:"innings"`
+	Balls       int     `json:"-"`
+	Overs       string  `json:"overs"`
+	Maidens     int     `json:"maidens"`
+	RunsGiven   int     `json:"runsGiven"`
+	Wickets     int     `json:"wickets"`
+	Economy     float64 `json:"economy"`
+	Average     float64 `json:"average"`
+	BestWickets int     `json:"-"`
+	BestRuns    int     `json:"-"`
+	Best        string  `json:"best"`
+}
+
+type fieldingStats struct {
+	Catches          int `json:"catches"`
+	RunOuts          int `json:"runOuts"`
+	KeeperDismissals int `json:"keeperDismissals"`
+}
+
+type seasonStats struct {
+	Series   string        `json:"series"`
+	Matches  int           `json:"matches"`
+	Batting  battingStats  `json:"batting"`
+	Bowling  bowlingStats  `json:"bowling"`
+	Fielding fieldingStats `json:"fielding"`
+}
+
+type playerProfile struct {
+	Player  string        `json:"player"`
+	Role    string        `json:"role"`
+	Career  seasonStats   `json:"career"`
+	Seasons []seasonStats `json:"seasons"`
+}
+
+// getPlayerProfile works out a player's career and per-season statistics
+// from every imported match.
+func getPlayerProfile(db *sql.DB, playerName string) (playerProfile, error) {
+
+	profile := playerProfile{Player: strings.TrimSpace(playerName), Career: seasonStats{Series: "Career"}}
+	role, err := getPlayerRole(db, playerName)
+	if err != nil {
+		return profile, err
+	}
+	profile.Role = role
+	seasons := make(map[string]*seasonStats)
+	seriesOrder := make([]string, 0)
+	season := func(series string) *seasonStats {
+		if _, ok := seasons[series]; !ok {
+			seasons[series] = &seasonStats{Series: series}
+			seriesOrder = append(seriesOrder, series)
+		}
+		return seasons[series]
+	}
+
+	// Batting
+	battingSQL := `select m.series, b.runs, b.balls, b.fours, b.sixers, b.Notout
+		from batsmen b JOIN "match" m ON b.matchid = m.matchid
+		where TRIM(b.battername) = TRIM(?) order by b.matchid`
+	err = queryRows(db, battingSQL, []interface{}{playerName}, func(row *sql.Rows) error {
+		var series string
+		var runs, balls, fours, sixers, notout int
+		if err := row.Scan(&series, &runs, &balls, &fours, &sixers, &notout); err != nil {
+			return err
+		}
+		for _, s := range []*seasonStats{&profile.Career, season(series)} {
+			s.Matches = s.Matches + 1
+			addBattingInnings(&s.Batting, runs, balls, fours, sixers, notout == 1)
+		}
+		return nil
+	})
+	if err != nil {
+		return profile, err
+	}
+
+	// Bowling
+	bowlingSQL := `select m.series, w.overs, w.Maidens, w.RunsGiven, w.wickets
+		from bowlers w JOIN "match" m ON w.matchid = m.matchid
+		where TRIM(w.bowlerName) = TRIM(?) order by w.matchid`
+	err = queryRows(db, bowlingSQL, []interface{}{playerName}, func(row *sql.Rows) error {
+		var series, overs string
+		var maidens, runsGiven, wickets int
+		if err := row.Scan(&series, &overs, &maidens, &runsGiven, &wickets); err != nil {
+			return err
+		}
+		for _, s := range []*seasonStats{&profile.Career, season(series)} {
+			addBowlingInnings(&s.Bowling, oversToBalls(overs), maidens, runsGiven, wickets)
+		}
+		return nil
+	})
+	if err != nil {
+		return profile, err
+	}
+
+	// Fielding
+	fieldingSQL := `select m.series, f.wicketType, f.catches, f.runouts
+		from fielders f JOIN "match" m ON f.matchid = m.matchid
+		where TRIM(f.fieldername) = TRIM(?) order by f.matchid`
+	err = queryRows(db, fieldingSQL, []interface{}{playerName}, func(row *sql.Rows) error {
+		var series, wicketType string
+		var catches, runouts int
+		if err := row.Scan(&series, &wicketType, &catches, &runouts); err != nil {
+			return err
+		}
+		for _, s := range []*seasonStats{&profile.Career, season(series)} {
+			if wicketType == "CaughtBehind" {
+				s.Fielding.KeeperDismissals = s.Fielding.KeeperDismissals + catches
+			} else {
+				s.Fielding.Catches = s.Fielding.Catches + catches
+			}
+			s.Fielding.RunOuts = s.Fielding.RunOuts + runouts
+		}
+		return nil
+	})
+	if err != nil {
+		return profile, err
+	}
+
+	for _, series := range seriesOrder {
+		finishStats(seasons[series])
+		profile.Seasons = append(profile.Seasons, *seasons[series])
+	}
+	finishStats(&profile.Career)
+	return profile, nil
+}
+
+func addBattingInnings(b *battingStats, runs int, balls int, fours int, sixers int, notout bool) {
+	if balls == 0 && runs == 0 {
+		// did not bat
+		return
+	}
+	b.Innings = b.Innings + 1
+	b.Runs = b.Runs + runs
+	b.Balls = b.Balls + balls
+	b.Fours = b.Fours + fours
+	b.Sixers = b.Sixers + sixers
+	if notout {
+		b.NotOuts = b.NotOuts + 1
+	}
+	if isDuck(runs, balls) {
+		b.Ducks = b.Ducks + 1
+	}
+	if runs > b.HighScore || (runs == b.HighScore && notout) {
+		b.HighScore = runs
+		b.HighNotOut = notout
+	}
+}
+
+func addBowlingInnings(w *bowlingStats, balls int, maidens int, runsGiven int, wickets int) {
+	if balls == 0 {
+		return
+	}
+	w.Innings = w.Innings + 1
+	w.Balls = w.Balls + balls
+	w.Maidens = w.Maidens + maidens
+	w.RunsGiven = w.RunsGiven + runsGiven
+	w.Wickets = w.Wickets + wickets
+	if w.Innings == 1 || wickets > w.BestWickets || (wickets == w.BestWickets && runsGiven < w.BestRuns) {
+		w.BestWickets = wickets
+		w.BestRuns = runsGiven
+	}
+}
+
+// finishStats fills in the averages and display values once all innings are added.
+func finishStats(s *seasonStats) {
+	b := &s.Batting
+	if b.Innings-b.NotOuts > 0 {
+		b.Average = float64(b.Runs) / float64(b.Innings-b.NotOuts)
+	}
+	if b.Balls > 0 {
+		b.StrikeRate = float64(b.Runs) * 100 / float64(b.Balls)
+	}
+	if b.Innings > 0 {
+		b.Highest = strconv.Itoa(b.HighScore)
+		if b.HighNotOut {
+			b.Highest = b.Highest + "*"
+		}
+	}
+
+	w := &s.Bowling
+	w.Overs = ballsToOvers(w.Balls)
+	if w.Balls > 0 {
+		w.Economy = float64(w.RunsGiven) * 6 / float64(w.Balls)
+		w.Best = strconv.Itoa(w.BestWickets) + "/" + strconv.Itoa(w.BestRuns)
+	}
+	if w.Wickets > 0 {
+		w.Average = float64(w.RunsGiven) / float64(w.Wickets)
+	}
+}
+
+// oversToBalls converts scorecard overs such as "3.2" (3 overs and 2 balls) to balls.
+func oversToBalls(overs string) int {
+	overSplits := strings.Split(strings.TrimSpace(overs), ".")
+	completed, _ := strconv.Atoi(overSplits[0])
+	balls := 0
+	if len(overSplits) > 1 {
+		balls, _ = strconv.Atoi(overSplits[1])
+	}
+	return completed*6 + balls
+}
+
+func ballsToOvers(balls int) string {
+	return strconv.Itoa(balls/6) + "." + strconv.Itoa(balls%6)
+}
+
+func renderPlayerProfile(profile playerProfile) {
+
+	seasons := append([]seasonStats{}, profile.Seasons...)
+	seasons = append(seasons, profile.Career)
+
+	batting := table.NewWriter()
+	batting.AppendHeader(table.Row{"Season", "Mat", "Inns", "NO", "Runs", "HS", "Avg", "SR", "4s", "6s", "Ducks"})
+	bowling := table.NewWriter()
+	bowling.AppendHeader(table.Row{"Season", "Inns", "Overs", "Mdns", "Runs", "Wkts", "Best", "Econ", "Avg"})
+	fielding := table.NewWriter()
+	fielding.AppendHeader(table.Row{"Season", "Catches", "Run Outs", "Keeper Dismissals"})
+
+	for _, s := range seasons {
+		b := s.Batting
+		batting.AppendRow(table.Row{s.Series, s.Matches, b.Innings, b.NotOuts, b.Runs, b.Highest, fmt.Sprintf("%.2f", b.Average), fmt.Sprintf("%.2f", b.StrikeRate), b.Fours, b.Sixers, b.Ducks})
+		w := s.Bowling
+		bowling.AppendRow(table.Row{s.Series, w.Innings, w.Overs, w.Maidens, w.RunsGiven, w.Wickets, w.Best, fmt.Sprintf("%.2f", w.Economy), fmt.Sprintf("%.2f", w.Average)})
+		f := s.Fielding
+		fielding.AppendRow(table.Row{s.Series, f.Catches, f.RunOuts, f.KeeperDismissals})
+	}
+
+	fmt.Println("------------------------------------------")
+	fmt.Println("Player Profile : " + profile.Player + " (" + profile.Role + ")")
+	fmt.Println("------------------------------------------")
+	fmt.Println("Batting")
+	fmt.Println(batting.Render())
+	fmt.Println("Bowling")
+	fmt.Println(bowling.Render())
+	fmt.Println("Fielding")
+	fmt.Println(fielding.Render())
+}
@@ -4,24 +4,27 @@ import (
 	"bufio"
 	"bytes"
 	"database/sql"
+	"errors"
 	"fmt"
 	_ "io/ioutil"
 	"log"
 	"os"
 	"os/exec"
+	"os/user"
 	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
+	"text/template"
+	"time"
 
 	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
 
 	_ "github.com/mattn/go-sqlite3" // Import go-sqlite3 library
 )
 
-var totalLines = 0
-var teams []string
-var linetext, matchDate, series, team1, team2, result string
+var matchDate, series, team1, team2, result string
 var division, stage string
 var matchid int
 var opponent string
@@ -30,13 +33,12 @@ var currentMatch int
 func main() {
 	//log.SetOutput(ioutil.Discard)
 	log.SetOutput(os.Stderr)
-	phoenixBatting := make([]string, 0)
-	phoenixBowling := make([]string, 0)
-	phoenixFielding := make([]string, 0)
 
 	if len(os.Args) <= 1 {
 		log.Println("Usage : " + os.Args[0] + " scorecard file.csv")
 		os.Exit(1)
+	} else if isCommand(os.Args[1]) {
+		runCommand(os.Args[1:])
 	} else if !(fileExists(os.Args[1])) {
 		log.Println("Scorecard csv file " + os.Args[1] + " Not found on the current directory.")
 		log.Println("Usage : " + os.Args[0] + " scorecard file.csv")
@@ -45,23 +47,116 @@ func main() {
 		log.Println(os.Args[1] + " is not a .csv file.")
 		log.Println("Usage : " + os.Args[0] + " scorecard file.csv")
 		os.Exit(1)
+	} else if err := validateScorecard(os.Args[1]); err != nil {
+		log.Println(os.Args[1] + " is not a valid scorecard : " + err.Error())
+		os.Exit(1)
+	} else if os.Getenv(databaseURLEnv) != "" {
+		log.Println("Importing with prompts works on phoenixPoints.db only, use " + os.Args[0] + " import " + os.Args[1] + " with " + databaseURLEnv + ".")
+		os.Exit(1)
 	} else {
 		dbconn := Dbconnect()
 		CreateTables(dbconn)
-		currentMatch = saveMatchDetails(os.Args[1], dbconn)
-		log.Println("Match Saved as Match ID := " + strconv.Itoa(currentMatch))
-		opponent = getOpponent(dbconn, currentMatch)
-		log.Println("Match Opponent := " + opponent)
-		phoenixBowling, phoenixBatting, phoenixFielding = extractRanges(os.Args[1])
-
-		processBatting(phoenixBatting, dbconn)
-		processBowling(phoenixBowling, dbconn)
-		processFielding(phoenixFielding, dbconn)
-		calculatePoints(dbconn)
+		importScorecard(os.Args[1], dbconn)
 		replacePlayer(dbconn)
 		renderFinalTable(dbconn)
-		dumpPointsTableAsCSV()
+		renderPointsBreakdown(dbconn)
+		exitOnError(writeMatchOutputs(os.Args[1], dbconn))
+	}
+}
+
+// importScorecard saves the match, the Phoenix batting, bowling and fielding
+// details from a scorecard csv and calculates the points, returning the match ID.
+func importScorecard(scorecard string, db *sql.DB) int {
+	phoenixBatting := make([]string, 0)
+	phoenixBowling := make([]string, 0)
+	phoenixFielding := make([]string, 0)
+
+	currentMatch = saveMatchDetails(scorecard, db)
+	log.Println("Match Saved as Match ID := " + strconv.Itoa(currentMatch))
+	opponent = getOpponent(db, currentMatch)
+	log.Println("Match Opponent := " + opponent)
+	phoenixBowling, phoenixBatting, phoenixFielding = extractRanges(scorecard)
+
+	processBatting(phoenixBatting, db)
+	processBowling(phoenixBowling, db)
+	processFielding(phoenixFielding, db)
+	saveInningsTotals(scorecard, db)
+	if err := calculatePoints(db); err != nil {
+		log.Fatalln(err.Error())
+	}
+	if err := announceRecords(db, currentMatch); err != nil {
+		log.Fatalln(err.Error())
+	}
+	return currentMatch
+}
+
+// writeMatchOutputs writes the points csv, HTML report and chat summary for the current match.
+func writeMatchOutputs(scorecard string, db *sql.DB) error {
+	dumpPointsTableAsCSV()
+	if err := writeHTMLReport(db, scorecard); err != nil {
+		return err
+	}
+	return writeMatchSummary(db)
+}
+
+// validateScorecard checks a scorecard csv has the match header and the
+// sections the import reads, so a bad file can be reported instead of
+// stopping the import part way.
+func validateScorecard(scorecard string) error {
+	f, err := os.Open(scorecard)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	header := make([]string, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() && len(header) < 2 {
+		header = append(header, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if len(header) < 2 || !strings.Contains(header[1], "Vs") {
+		return errors.New("line 2 should name the teams as \"Team1 Vs Team2\"")
+	}
+
+	teamSplits := strings.Split(header[1], "Vs")
+	oppo := strings.Trim(teamSplits[1], " ")
+	if strings.Trim(teamSplits[1], " ") == "Phoenix" {
+		oppo = strings.Trim(teamSplits[0], " ")
+	} else if strings.Trim(teamSplits[0], " ") != "Phoenix" {
+		return errors.New("Phoenix is not one of the teams in \"" + strings.TrimSpace(header[1]) + "\"")
+	}
+
+	sections := []struct {
+		startPattern string
+		endPattern   string
+		fields       int
+	}{
+		{"Phoenix Batting", "Byes:", 8},
+		{"Phoenix Bowling", "Total,", 7},
+		{oppo + " Batting", "Byes:", 4},
+	}
+	for _, section := range sections {
+		start, end, err := calculateRanges(scorecard, section.startPattern, section.endPattern)
+		if err != nil {
+			return err
+		}
+		if start == 0 || end == 0 {
+			return errors.New("no \"" + section.startPattern + "\" section ending in \"" + section.endPattern + "\"")
+		}
+		lines, err := extractRange(scorecard, start, end, section.startPattern, section.endPattern)
+		if err != nil {
+			return err
+		}
+		for _, line := range lines {
+			if len(strings.Split(line, ",")) < section.fields {
+				return errors.New(section.startPattern + " line \"" + line + "\" has fewer than " + strconv.Itoa(section.fields) + " fields")
+			}
+		}
 	}
+	return nil
 }
 
 func processBatting(battingArray []string, db *sql.DB) {
@@ -73,28 +168,10 @@ func processBatting(battingArray []string, db *sql.DB) {
 		log.Fatalln(err.Error())
 	}
 
-	for i := 0; i < len(battingArray); i++ {
-		battingSplits := strings.Split(battingArray[i], ",")
-		if battingSplits[0] != "BatsMan" {
-			var ballsfaced int
-			ballsfaced, _ = strconv.Atoi(battingSplits[5])
-
-			if len(battingSplits[1]) == 0 && ballsfaced > 0 {
-
-				// Nothing on "how Out" but more than 1 ball faced, means they are not out
-				_, err = statement.Exec(matchid, battingSplits[0], battingSplits[4], battingSplits[5], battingSplits[6], battingSplits[7], 1)
-				if err != nil {
-					log.Fatalln(err.Error())
-				}
-			} else {
-				// Batsman is out
-				_, err = statement.Exec(matchid, battingSplits[0], battingSplits[4], battingSplits[5], battingSplits[6], battingSplits[7], 0)
-				if err != nil {
-					log.Fatalln(err.Error())
-				}
-
-			}
-
+	for _, b := range parseBatting(battingArray) {
+		_, err = statement.Exec(matchid, b.Name, b.Runs, b.Balls, b.Fours, b.Sixers, b.NotOut)
+		if err != nil {
+			log.Fatalln(err.Error())
 		}
 	}
 	log.Println("Phoenix Batting Details inserted ...")
@@ -109,13 +186,10 @@ func processBowling(bowlingArray []string, db *sql.DB) {
 		log.Fatalln(err.Error())
 	}
 
-	for i := 0; i < len(bowlingArray); i++ {
-		bowlingSplits := strings.Split(bowlingArray[i], ",")
-		if bowlingSplits[0] != "Bowler" {
-			_, err = statement.Exec(matchid, bowlingSplits[0], bowlingSplits[1], bowlingSplits[2], bowlingSplits[3], bowlingSplits[4], bowlingSplits[5], bowlingSplits[6])
-			if err != nil {
-				log.Fatalln(err.Error())
-			}
+	for _, w := range parseBowling(bowlingArray) {
+		_, err = statement.Exec(matchid, w.Name, w.Overs, w.Maidens, w.RunsGiven, w.Wickets, w.Wides, w.NoBalls)
+		if err != nil {
+			log.Fatalln(err.Error())
 		}
 	}
 	log.Println("Phoenix Bowling Details inserted ...")
@@ -130,63 +204,12 @@ func processFielding(fieldingArray []string, db *sql.DB) {
 		log.Fatalln(err.Error())
 	}
 
-	for i := 0; i < len(fieldingArray); i++ {
-		fieldingSplits := strings.Split(fieldingArray[i], ",")
-		if fieldingSplits[0] != "BatsMan" {
-
-			// Get full PlayerName from Batsman table
-
-			fullFielderName := getFullPlayerName(db, matchid, fieldingSplits[2])
-			fullBowlerName := getFullPlayerName(db, matchid, fieldingSplits[3])
-
-			// find the dismissal Type
-			switch fieldingSplits[1] {
-			case "ct":
-				//find if its a caught and Bowled - if bowler == Fielder
-				if fieldingSplits[2] == fieldingSplits[3] {
-					// Its a Caught and Bowled
-					_, err = statement.Exec(matchid, fieldingSplits[0], "Caught&Bowled", fullFielderName, fullFielderName, 0, 1, 0)
-					if err != nil {
-						log.Fatalln(err.Error())
-					}
-				} else {
-					// Its a catch
-					_, err = statement.Exec(matchid, fieldingSplits[0], "Caught", fullFielderName, fullBowlerName, 0, 1, 0)
-					if err != nil {
-						log.Fatalln(err.Error())
-					}
-				}
-			case "b":
-				_, err = statement.Exec(matchid, fieldingSplits[0], "Bowled", "", fullBowlerName, 1, 0, 0)
-				if err != nil {
-					log.Fatalln(err.Error())
-				}
-			case "ro":
-				// Find if its a Direct Hit - If Filder Name is null , then its a Direct Hit
-				if strings.Trim(fieldingSplits[2], " ") == "" {
-					// Its a Direct Hit
-					_, err = statement.Exec(matchid, fieldingSplits[0], "RunOut-DirectHit", fullBowlerName, "", 0, 0, 1)
-					if err != nil {
-						log.Fatalln(err.Error())
-					}
-				} else {
-					// Simple Runout , 2 fielders are involved , give runout credit to both
-					_, err = statement.Exec(matchid, fieldingSplits[0], "RunOut", fullFielderName, "", 0, 0, 1)
-					if err != nil {
-						log.Fatalln(err.Error())
-					}
-					_, err = statement.Exec(matchid, fieldingSplits[0], "RunOut", fullBowlerName, "", 0, 0, 1)
-					if err != nil {
-						log.Fatalln(err.Error())
-					}
-				}
-			case "ctw":
-				// Caught Behind
-				_, err = statement.Exec(matchid, fieldingSplits[0], "CaughtBehind", fullFielderName, fullBowlerName, 0, 1, 0)
-				if err != nil {
-					log.Fatalln(err.Error())
-				}
-			}
+	// Get full PlayerName from Batsman table
+	fullName := func(partialName string) string { return getFullPlayerName(db, matchid, partialName) }
+	for _, f := range parseFielding(fieldingArray, fullName) {
+		_, err = statement.Exec(matchid, f.Batsman, f.WicketType, f.Fielder, f.Bowler, f.Bowled, f.Catches, f.RunOuts)
+		if err != nil {
+			log.Fatalln(err.Error())
 		}
 	}
 	log.Println("Phoenix Fielding Details inserted ...")
@@ -201,13 +224,36 @@ func fileExists(filename string) bool {
 }
 
 func saveMatchDetails(scorecard string, db *sql.DB) int {
+	if _, err := parseMatchDetails(scorecard); err != nil {
+		log.Fatal(err)
+	}
+	InsertMatchDetails(db, series, stage, division, matchDate, team1, team2, result)
+	return getMatchId(db)
+}
+
+// parseMatchDetails reads the match header into the package variables the
+// single file import uses, and returns it.
+func parseMatchDetails(scorecard string) (matchDetails, error) {
+	m, err := readMatchHeader(scorecard)
+	if err != nil {
+		return m, err
+	}
+	series, stage, division, matchDate, team1, team2, result = m.Series, m.Stage, m.Division, m.MatchDate, m.Team1, m.Team2, m.Result
+	return m, nil
+}
+
+// readMatchHeader reads the match header, the first two lines of the
+// scorecard. It keeps to local variables so files can be read concurrently.
+func readMatchHeader(scorecard string) (matchDetails, error) {
 	f, err := os.Open(scorecard)
 	if err != nil {
-		log.Fatal(err)
+		return matchDetails{}, err
 	}
 	defer f.Close()
 
 	scanner := bufio.NewScanner(f)
+	totalLines := 0
+	var linetext, matchDate, series, team1, team2, result, division, stage string
 
 	for scanner.Scan() {
 		linetext = scanner.Text()
@@ -244,7 +290,7 @@ func saveMatchDetails(scorecard string, db *sql.DB) int {
 
 		// Team1 & Team2
 		if totalLines == 2 {
-			teams = strings.Split(linetext, "Vs")
+			teams := strings.Split(linetext, "Vs")
 			team1 = strings.Trim(teams[0], " ")
 			team2 = strings.Trim(teams[1], " ")
 		}
@@ -252,14 +298,12 @@ func saveMatchDetails(scorecard string, db *sql.DB) int {
 		if totalLines > 2 {
 			break
 		}
-
-		if err := scanner.Err(); err != nil {
-			log.Fatal(err)
-		}
+	}
+	if err := scanner.Err(); err != nil {
+		return matchDetails{}, err
 	}
 
-	InsertMatchDetails(db, series, stage, division, matchDate, team1, team2, result)
-	return getMatchId(db)
+	return matchDetails{Series: series, Stage: stage, Division: division, MatchDate: matchDate, Team1: team1, Team2: team2, Result: result}, nil
 }
 
 func getMatchId(db *sql.DB) int {
@@ -277,81 +321,26 @@ func getMatchId(db *sql.DB) int {
 
 func Dbconnect() *sql.DB {
 	log.Println("Creating SQLite3 connection to phoenixPoints db...")
-	phoenixdb, _ := sql.Open("sqlite3", "./phoenixPoints.db")
+	// foreign keys are off in SQLite unless turned on for each connection
+	phoenixdb, _ := sql.Open("sqlite3", "./phoenixPoints.db?_foreign_keys=on")
 	log.Println("Connection created to phoenixPoints db..")
 	return phoenixdb
 }
 
+// CreateTables brings a new database up to the current schema. A database
+// that already has matches but is behind must be upgraded with the migrate
+// command, which backs it up first.
 func CreateTables(db *sql.DB) {
-	createMatchTableSQL := `CREATE TABLE IF NOT EXISTS match (
-		"matchid" integer NOT NULL PRIMARY KEY AUTOINCREMENT,		
-		"series" TEXT,
-		"stage" TEXT,
-		"division" TEXT	,
-		"matchDate" TEXT,
-		"Team1" TEXT,
-		"Team2" TEXT,
-		"Result" TEXT	
-	  );`
-
-	createPhoenixBowlers := `CREATE TABLE IF NOT EXISTS bowlers (
-		"matchid" INTEGER,
-		"bowlerName" TEXT,
-		"overs" TEXT,
-		"Maidens" INTEGER DEFAULT 0,
-		"RunsGiven" INTEGER DEFAULT 0,
-		"wickets" INTEGER DEFAULT 0,
-		"Wides" INTEGER DEFAULT 0,
-		"NoBalls" INTEGER DEFAULT 0
-	  );`
-
-	createPhoenixBatsmen := `CREATE TABLE IF NOT EXISTS batsmen (
-		"matchid" INTEGER,
-		"battername" TEXT,
-		"runs" INTEGER DEFAULT 0,
-		"balls" INTEGER DEFAULT 0,
-		"fours" INTEGER DEFAULT 0,
-		"sixers" INTEGER DEFAULT 0,
-		"Notout" INTEGER DEFAULT 0
-	  );`
-
-	createPhoenixFielding := `CREATE TABLE IF NOT EXISTS fielders (
-		"matchid" INTEGER,
-		"Batsman" TEXT,
-		"wicketType" TEXT,
-		"fieldername" TEXT,
-		"bowlername" TEXT,
-		"bowled" INTEGER DEFAULT 0,
-		"catches" INTEGER DEFAULT 0,
-		"runouts" INTEGER DEFAULT 0
-	  );`
-
-	/* createPointsTableSQL := `CREATE TABLE IF NOT EXISTS points (
-		"matchid" integer ,
-		"player" TEXT,
-		"RunsScored" INTEGER DEFAULT 0,
-		"Boundries" INTEGER DEFAULT 0,
-		"NotOut"	INTEGER DEFAULT 0,
-		"Duck"		INTEGER DEFAULT 0,
-		"Wicket"	INTEGER DEFAULT 0,
-		"Bowled"	INTEGER DEFAULT 0,
-		"Maiden"	INTEGER DEFAULT 0,
-		"BNetRR"	INTEGER DEFAULT 0,
-		"Extras"	INTEGER DEFAULT 0,
-		"Catch"		INTEGER DEFAULT 0,
-		"CaughtAndBowled"	INTEGER DEFAULT 0,
-		"Runout"	INTEGER DEFAULT 0,
-		"DropCatch" INTEGER DEFAULT 0,
-		"MatchResult"	INTEGER DEFAULT 0,
-		"Total"		INTEGER	DEFAULT 0
-	  );` */
-
-	execQuery(db, createMatchTableSQL, "Creating Match Table")
-	execQuery(db, createPhoenixBowlers, "Creating bowlers Table")
-	execQuery(db, createPhoenixBatsmen, "Creating Batter Table")
-	execQuery(db, createPhoenixFielding, "Creating Fielders Table")
-	//execQuery(db, createPointsTableSQL, "Creating Points Table")
-
+	pending := pendingMigrations(db)
+	if len(pending) == 0 {
+		return
+	}
+	if !hasTable(db, "match") {
+		applyMigrations(db, pending)
+		return
+	}
+	log.Fatalln("phoenixPoints.db is at schema version " + fmt.Sprint(schemaVersion(db)) + ", this version needs " +
+		fmt.Sprint(pending[len(pending)-1].Version) + ". Run ./readcsv migrate to back it up and upgrade it.")
 }
 
 func InsertMatchDetails(db *sql.DB, series string, stage string, division string, matchDate string, team1 string, team2 string, result string) {
@@ -369,17 +358,75 @@ func InsertMatchDetails(db *sql.DB, series string, stage string, division string
 	log.Println("Match Details inserted ...")
 }
 
-func execQuery(db *sql.DB, query string, querycomment string) {
+func execQuery(db *sql.DB, query string, querycomment string) error {
 	statement, err := db.Prepare(query) // Prepare statement.
 	// This is good to avoid SQL injections
 	if err != nil {
-		log.Fatalln(err.Error())
+		return err
 	}
+	defer statement.Close()
 	_, err = statement.Exec()
 	if err != nil {
-		log.Fatalln(err.Error())
+		return err
 	}
 	log.Println(querycomment)
+	return nil
+}
+
+// queryRows runs a query and calls scan for each row, stopping at the
+// first error.
+func queryRows(db *sql.DB, query string, args []interface{}, scan func(row *sql.Rows) error) error {
+	row, err := db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer row.Close()
+	for row.Next() {
+		if err := scan(row); err != nil {
+			return err
+		}
+	}
+	return row.Err()
+}
+
+type matchDetails struct {
+	MatchID   int    `json:"matchid"`
+	Series    string `json:"series"`
+	Stage     string `json:"stage"`
+	Division  string `json:"division"`
+	MatchDate string `json:"matchDate"`
+	Team1     string `json:"team1"`
+	Team2     string `json:"team2"`
+	Result    string `json:"result"`
+}
+
+func getMatches(db *sql.DB) ([]matchDetails, error) {
+
+	row, err := db.Query("select matchid, series, stage, division, matchDate, Team1, Team2, Result from match order by matchid")
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	matches := make([]matchDetails, 0)
+	for row.Next() {
+		m := matchDetails{}
+		row.Scan(&m.MatchID, &m.Series, &m.Stage, &m.Division, &m.MatchDate, &m.Team1, &m.Team2, &m.Result)
+		matches = append(matches, m)
+	}
+	return matches, row.Err()
+}
+
+// getMatchDetails reads a match, a not found error when there is no such match.
+func getMatchDetails(db *sql.DB, matchid int) (matchDetails, error) {
+
+	m := matchDetails{}
+	matchSQL := "select matchid, series, stage, division, matchDate, Team1, Team2, Result from match where matchid = ?"
+	err := db.QueryRow(matchSQL, matchid).Scan(&m.MatchID, &m.Series, &m.Stage, &m.Division, &m.MatchDate, &m.Team1, &m.Team2, &m.Result)
+	if err == sql.ErrNoRows {
+		return m, notFoundError("there is no Match ID " + strconv.Itoa(matchid))
+	}
+	return m, err
 }
 
 func getOpponent(db *sql.DB, matchid int) string {
@@ -468,60 +515,75 @@ func getBowlersInthisMatch(db *sql.DB, matchid int) [11]string {
 	return Bowlers
 }
 
-func checkPlayerThere(db *sql.DB, matchid int, playerName string) bool {
+func checkPlayerThere(db queryRower, matchid int, playerName string) (bool, error) {
 
 	var pname string
-	pSQL := "select battername  from batsmen  where matchid =" + strconv.Itoa(matchid) + " AND TRIM(battername) = TRIM(\"" + playerName + "\") LIMIT 1;"
-	stmt, err := db.Prepare(pSQL)
-	if err != nil {
-		log.Fatal(err)
+	pSQL := "select battername  from batsmen  where matchid = ? AND TRIM(battername) = TRIM(?) LIMIT 1"
+	err := db.QueryRow(pSQL, matchid, playerName).Scan(&pname)
+	if err == sql.ErrNoRows {
+		return false, nil
 	}
-	err = stmt.QueryRow().Scan(&pname)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return false
-		}
-	}
-	return true
+	return err == nil, err
 }
 
 func replacePlayerAllTables(db *sql.DB, matchid int, playerName [11]string, newplayerName [11]string) {
 
-	updateBatsmenSQL := `update batsmen SET battername=TRIM(?) where TRIM(battername)=TRIM(?) AND matchid = ?`
-	updateBowlerSQL := `update bowlers SET bowlerName=TRIM(?) where TRIM(bowlerName)=TRIM(?) AND matchid = ?`
-	updateFieldersSQL1 := `update fielders SET fieldername=TRIM(?) where TRIM(fieldername)=TRIM(?) AND matchid = ?`
-	updateFieldersSQL2 := `update fielders SET bowlername=TRIM(?) where TRIM(bowlername)=TRIM(?) AND matchid = ?`
-	updatePointsSQL := `update TotalMatchPoints SET Player=TRIM(?) where TRIM(Player)=TRIM(?) AND matchid = ?`
-
 	for i := 0; i < len(playerName); i++ {
-		execPlayerUpdateQuery(db, updateBatsmenSQL, "Batsmen Table Updated.....", matchid, playerName[i], newplayerName[i])
-		execPlayerUpdateQuery(db, updateBowlerSQL, "Bowlers Table Updated.....", matchid, playerName[i], newplayerName[i])
-		execPlayerUpdateQuery(db, updateFieldersSQL1, "Fielder Table Updated for Fielder Names.....", matchid, playerName[i], newplayerName[i])
-		execPlayerUpdateQuery(db, updateFieldersSQL2, "Fielder Table Updated for Bowler Names.....", matchid, playerName[i], newplayerName[i])
-		execPlayerUpdateQuery(db, updatePointsSQL, "Points Table updated for Player Name .....", matchid, playerName[i], newplayerName[i])
+		if _, err := replacePlayerName(db, matchid, playerName[i], newplayerName[i], auditAtImport); err != nil {
+			log.Fatalln(err.Error())
+		}
 	}
 
 	log.Println("All Name updates Done ...")
 }
 
-func execPlayerUpdateQuery(db *sql.DB, query string, querycomment string, matchid int, playerName string, newPlayerName string) {
-
-	statement, err := db.Prepare(query) // Prepare statement.
-	// This is good to avoid SQL injections
+// replacePlayerName replaces a player's name in a match and keeps the
+// replacement in the audit log, returning its audit ID, a not found error
+// when the player did not bat in the match.
+func replacePlayerName(db dbExecer, matchid int, playerName string, newplayerName string, reason string) (int64, error) {
+	if strings.TrimSpace(playerName) == strings.TrimSpace(newplayerName) {
+		return 0, nil
+	}
+	there, err := checkPlayerThere(db, matchid, playerName)
 	if err != nil {
-		log.Fatalln(err.Error())
+		return 0, err
+	}
+	if !there {
+		return 0, notFoundError(strings.TrimSpace(playerName) + " is not in Match ID " + strconv.Itoa(matchid))
 	}
+	if err := updatePlayerName(db, matchid, playerName, newplayerName); err != nil {
+		return 0, err
+	}
+	return recordAudit(db, auditEntry{Kind: auditRename, MatchID: matchid, Player: playerName, Field: "name",
+		OldValue: strings.TrimSpace(playerName), NewValue: strings.TrimSpace(newplayerName), Reason: reason})
+}
 
-	_, err = statement.Exec(newPlayerName, playerName, matchid)
+func updatePlayerName(db dbExecer, matchid int, playerName string, newplayerName string) error {
 
-	if err != nil {
-		log.Fatalln(err.Error())
+	updateBatsmenSQL := `update batsmen SET battername=TRIM(?) where TRIM(battername)=TRIM(?) AND matchid = ?`
+	updateBowlerSQL := `update bowlers SET bowlerName=TRIM(?) where TRIM(bowlerName)=TRIM(?) AND matchid = ?`
+	updateFieldersSQL1 := `update fielders SET fieldername=TRIM(?) where TRIM(fieldername)=TRIM(?) AND matchid = ?`
+	updateFieldersSQL2 := `update fielders SET bowlername=TRIM(?) where TRIM(bowlername)=TRIM(?) AND matchid = ?`
+	updatePointsSQL := `update TotalMatchPoints SET Player=TRIM(?) where TRIM(Player)=TRIM(?) AND matchid = ?`
+	updateAdjustmentsSQL := `update adjustments SET player=TRIM(?) where TRIM(player)=TRIM(?) AND matchid = ?`
+
+	for _, query := range []string{updateBatsmenSQL, updateBowlerSQL, updateFieldersSQL1, updateFieldersSQL2, updatePointsSQL, updateAdjustmentsSQL} {
+		if err := execPlayerUpdateQuery(db, query, matchid, playerName, newplayerName); err != nil {
+			return err
+		}
 	}
+	log.Println("Player Name updated in all Tables .....")
+	return nil
+}
+
+func execPlayerUpdateQuery(db dbExecer, query string, matchid int, playerName string, newPlayerName string) error {
+	_, err := db.Exec(query, newPlayerName, playerName, matchid)
+	return err
 }
 
 func exec1RunOverUpdate(db *sql.DB, matchid int, Bowlers [11]string, OneRunOvers [11]int) {
 
-	updateQuery := `UPDATE TotalMatchPoints SET OneRunOvers= ?*5 WHERE matchid=? AND TRIM(Player) = TRIM(?)`
+	updateQuery := `UPDATE TotalMatchPoints SET OneRunOvers= ?*? WHERE matchid=? AND TRIM(Player) = TRIM(?)`
 	statement, err := db.Prepare(updateQuery) // Prepare statement.
 	// This is good to avoid SQL injections
 	if err != nil {
@@ -530,19 +592,21 @@ func exec1RunOverUpdate(db *sql.DB, matchid int, Bowlers [11]string, OneRunOvers
 
 	for i := 0; i < len(Bowlers); i++ {
 		if Bowlers[i] != "" {
-			_, err = statement.Exec(OneRunOvers[i], matchid, Bowlers[i])
+			_, err = statement.Exec(OneRunOvers[i], activeRules.OneRunOver, matchid, Bowlers[i])
+			if err != nil {
+				log.Fatalln(err.Error())
+			}
+			if _, err = recordAdjustment(db, matchid, Bowlers[i], "OneRunOvers", OneRunOvers[i], auditAtImport); err != nil {
+				log.Fatalln(err.Error())
+			}
 		}
 	}
-
-	if err != nil {
-		log.Fatalln(err.Error())
-	}
 	log.Println("1 Run Overs Updated... ")
 }
 
 func exec1DropCatches(db *sql.DB, matchid int, Players [11]string, DropCatches [11]int) {
 
-	updateQuery := `UPDATE TotalMatchPoints SET DropCatches = ?*-3 WHERE matchid=? AND TRIM(Player) = TRIM(?)`
+	updateQuery := `UPDATE TotalMatchPoints SET DropCatches = ?*? WHERE matchid=? AND TRIM(Player) = TRIM(?)`
 	statement, err := db.Prepare(updateQuery) // Prepare statement.
 	// This is good to avoid SQL injections
 	if err != nil {
@@ -550,139 +614,289 @@ func exec1DropCatches(db *sql.DB, matchid int, Players [11]string, DropCatches [
 	}
 
 	for i := 0; i < len(Players); i++ {
-		_, err = statement.Exec(DropCatches[i], matchid, Players[i])
+		_, err = statement.Exec(DropCatches[i], activeRules.DropCatch, matchid, Players[i])
+		if err != nil {
+			log.Fatalln(err.Error())
+		}
+		if Players[i] != "" {
+			if _, err = recordAdjustment(db, matchid, Players[i], "DropCatches", DropCatches[i], auditAtImport); err != nil {
+				log.Fatalln(err.Error())
+			}
+		}
 	}
+	log.Println("Drop Catches Updated... ")
+}
 
+// adjustmentPoints are the manually entered TotalMatchPoints columns and the points per unit entered.
+var adjustmentPoints = map[string]int{
+	"OneRunOvers": activeRules.OneRunOver,
+	"DropCatches": activeRules.DropCatch,
+}
+
+// setAdjustment enters one manual adjustment for a player, e.g. 2 OneRunOvers.
+func setAdjustment(db *sql.DB, matchid int, playerName string, field string, value int, reason string) error {
+
+	perUnit, ok := adjustmentPoints[field]
+	if !ok {
+		return invalidError(field + " is not a manual adjustment, use OneRunOvers or DropCatches")
+	}
+	updateQuery := `UPDATE TotalMatchPoints SET ` + field + ` = ?*? WHERE matchid=? AND TRIM(Player) = TRIM(?)`
+	res, err := db.Exec(updateQuery, value, perUnit, matchid, playerName)
 	if err != nil {
-		log.Fatalln(err.Error())
+		return err
 	}
-	log.Println("Drop Catches Updated... ")
+	if n, _ := res.RowsAffected(); n == 0 {
+		return notFoundError(playerName + " did not play in Match ID " + strconv.Itoa(matchid))
+	}
+	_, err = recordAdjustment(db, matchid, playerName, field, value, reason)
+	return err
 }
 
-func calculatePoints(db *sql.DB) {
+// applyAdjustments puts the manual adjustments back after the points table
+// is rebuilt, the last value entered for a player's match and field.
+func applyAdjustments(db *sql.DB) error {
+	for field, perUnit := range adjustmentPoints {
+		if _, err := db.Exec(adjustmentSQL(sqliteDialect, field), perUnit, field); err != nil {
+			return err
+		}
+	}
+	log.Println("Manual Adjustments Applied")
+	return nil
+}
 
-	dropPointsTableSQL := `DROP TABLE IF EXISTS TotalMatchPoints`
-	createPointsTableSQL := `CREATE TABLE TotalMatchPoints AS 
+// recordAdjustment keeps who entered a manual points adjustment and when,
+// so it can be explained later, and logs a changed value in the audit log,
+// returning its audit ID, 0 when the value did not change.
+func recordAdjustment(db dbExecer, matchid int, playerName string, field string, value int, reason string) (int64, error) {
+	old, err := latestAdjustment(db, matchid, playerName, field)
+	if err != nil {
+		return 0, err
+	}
+	insertAdjustmentSQL := `INSERT INTO adjustments (matchid,player,field,value,enteredBy,enteredAt) VALUES (?,TRIM(?),?,?,?,?)`
+	_, err = db.Exec(insertAdjustmentSQL, matchid, playerName, field, value, currentUser(), time.Now().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return 0, err
+	}
+	if old == strconv.Itoa(value) {
+		return 0, nil
+	}
+	return recordAudit(db, auditEntry{Kind: auditAdjustment, MatchID: matchid, Player: playerName, Field: field,
+		OldValue: old, NewValue: strconv.Itoa(value), Reason: reason})
+}
+
+func currentUser() string {
+	u, err := user.Current()
+	if err != nil {
+		return os.Getenv("USER")
+	}
+	return u.Username
+}
+
+// sqlDialect is how the points SQL differs between SQLite and PostgreSQL.
+type sqlDialect struct {
+	Real  string // the type to divide figures as for the economy
+	ILike string // the case insensitive LIKE
+	Param string // the prefix of a numbered parameter, ?1 or $1
+}
+
+var sqliteDialect = sqlDialect{Real: "REAL", ILike: "LIKE", Param: "?"}
+
+func (d sqlDialect) param(n int) string {
+	return d.Param + strconv.Itoa(n)
+}
+
+// pointsTableTemplate builds TotalMatchPoints from the stats with the point
+// values in a pointsRules, the same values explain uses, for a sqlDialect.
+var pointsTableTemplate = template.Must(template.New("points").Parse(`CREATE TABLE TotalMatchPoints AS
 
 	select
-		* ,
-		0 as "OneRunOvers",
-		0 as "DropCatches",
+		T.* ,
+		0 as OneRunOvers,
+		0 as DropCatches,
+		0 as KeeperBonus,
 		T.RunsScored + T.Boundries + T.NotOut + T.Duck + T.wicket + T.Maidens + T.NRR + T.Extras + T.Bowled + T.catch + T.runouts + T.MatchWon as "Total Points"
 	from
 		(
 		SELECT
 			b.matchid,
-			b.battername "Player",
-			b.runs * 2 as "RunsScored" ,
-			b.fours * 5 as "Boundries" ,
-			b.Notout * 2 as "NotOut" ,
+			b.battername as Player,
+			b.runs * {{.Run}} as RunsScored ,
+			b.fours * {{.Four}} as Boundries ,
+			b.Notout * {{.NotOut}} as NotOut ,
 			CASE
-				WHEN (b.runs > 0
-				AND b.balls>0) THEN 0
-				WHEN b .balls = 0 THEN 0
-				ELSE -3
-			END as "Duck",
+				WHEN ` + duckSQL + ` THEN {{.Duck}}
+				ELSE 0
+			END as Duck,
 			CASE
-				WHEN w.wickets > 0 THEN w.wickets * 10
+				WHEN w.wickets > 0 THEN w.wickets * {{.Wicket}}
 				ELSE 0
-			END as "wicket",
+			END as wicket,
 			CASE
-				WHEN w.Maidens > 0 THEN w.Maidens * 5
+				WHEN w.Maidens > 0 THEN w.Maidens * {{.Maiden}}
 				ELSE 0
-			END as "Maidens",
+			END as Maidens,
 			CASE
-				WHEN (w.RunsGiven / w.overs) <= 5 THEN 5
-				WHEN (w.RunsGiven / w.overs)>= 7 THEN -3
+				WHEN (CAST(w.RunsGiven AS {{.Real}}) / NULLIF(CAST(NULLIF(w.overs, '') AS {{.Real}}), 0)) <= {{.GoodEconomyMax}} THEN {{.GoodEconomy}}
+				WHEN (CAST(w.RunsGiven AS {{.Real}}) / NULLIF(CAST(NULLIF(w.overs, '') AS {{.Real}}), 0)) >= {{.PoorEconomyMin}} THEN {{.PoorEconomy}}
 				ELSE 0
-			END as "NRR",
+			END as NRR,
 			CASE
-				WHEN w.overs > 0 THEN
+				WHEN CAST(NULLIF(w.overs, '') AS {{.Real}}) > 0 THEN
 				(CASE
-					WHEN (w.Wides + w.NoBalls) >= 1 THEN (w.Wides + w.NoBalls)*-2
-					ELSE 3
+					WHEN (w.Wides + w.NoBalls) >= 1 THEN (w.Wides + w.NoBalls) * {{.Extra}}
+					ELSE {{.NoExtras}}
 				END)
 				ELSE 0
-			END as "Extras",
+			END as Extras,
 			CASE
 				WHEN p.Bowled > 0 THEN p.Bowled
 				ELSE 0
-			END as "Bowled",
+			END as Bowled,
 			CASE
 				WHEN q.catch > 0 THEN q.catch
 				ELSE 0
-			END as "catch",
+			END as catch,
 			CASE
 				WHEN q.runouts > 0 THEN q.runouts
 				ELSE 0
-			END as "runouts",
+			END as runouts,
 			CASE
-				WHEN m."Result" Like "Phoenix Won%" THEN 10
+				WHEN m.Result {{.ILike}} 'Phoenix Won%' THEN {{.MatchWon}}
 				ELSE 0
-			END as "MatchWon",
-			m.matchDate "MatchDate",
+			END as MatchWon,
+			m.matchDate as MatchDate,
 			CASE
-				WHEN m.Team1 = "Phoenix" THEN m.Team2
+				WHEN m.Team1 = 'Phoenix' THEN m.Team2
 				ELSE m.Team1
-			END as "Opponent"
+			END as Opponent
 		FROM
 			batsmen b
 		LEFT JOIN bowlers w ON
 			b.matchid = w.matchid
 			AND b.battername = w.bowlerName
-		LEFT JOIN 
+		LEFT JOIN
 	(
 			SELECT
-				b1.battername as "battername",
-				sum(f1.bowled * 2) as "Bowled"
+				f1.matchid,
+				f1.bowlername as battername,
+				sum(f1.bowled * {{.Bowled}}) as Bowled
 			FROM
-				batsmen b1
-			JOIN fielders f1 ON
-				b1.matchid = f1.matchid
-				AND b1.battername = f1.bowlername
+				fielders f1
 			group by
-				b1.battername) p ON
-			b.battername = p.battername
+				f1.matchid,
+				f1.bowlername) p ON
+			b.matchid = p.matchid
+			AND b.battername = p.battername
 		LEFT JOIN
 	(
 			SELECT
-				b2.battername "battername",
-				sum(f2.catches * 8) as "catch",
-				CASE WHEN f2.wicketType="RunOut-DirectHit" THEN sum(f2.runouts * 4) ELSE sum(f2.runouts * 3)  END as "runouts"
+				f2.matchid,
+				f2.fieldername as battername,
+				sum(f2.catches * {{.Catch}}) as catch,
+				sum(CASE WHEN f2.wicketType = 'RunOut-DirectHit' THEN f2.runouts * {{.DirectHit}} ELSE f2.runouts * {{.RunOut}} END) as runouts
 			FROM
-				batsmen b2
-			JOIN fielders f2 ON
-				b2.matchid = f2.matchid
-				AND b2.battername = f2.fieldername
+				fielders f2
 			group by
-				b2.battername ) q ON
-			b.battername = q.battername
+				f2.matchid,
+				f2.fieldername ) q ON
+			b.matchid = q.matchid
+			AND b.battername = q.battername
 		JOIN "match" m ON
 			b.matchid = m.matchid ) T
-	`
-	execQuery(db, dropPointsTableSQL, "Dropping Points Table")
-	execQuery(db, createPointsTableSQL, "Creating Points Table with this Match details.....")
+	`))
+
+// pointsTableSQL is the CREATE TABLE statement for TotalMatchPoints under
+// rules in dialect d.
+func pointsTableSQL(rules pointsRules, d sqlDialect) (string, error) {
+	var createSQL strings.Builder
+	err := pointsTableTemplate.Execute(&createSQL, struct {
+		pointsRules
+		sqlDialect
+	}{rules, d})
+	return createSQL.String(), err
+}
+
+// adjustmentSQL puts the last value entered for a manual adjustment field
+// back in the points, taking the points per unit and the field.
+func adjustmentSQL(d sqlDialect, field string) string {
+	return `UPDATE TotalMatchPoints SET ` + field + ` = ` + d.param(1) + ` * (
+			select a.value from adjustments a
+			where a.matchid = TotalMatchPoints.matchid AND TRIM(a.player) = TRIM(TotalMatchPoints.Player) AND a.field = ` + d.param(2) + `
+			order by a.adjustmentid DESC limit 1)
+			WHERE EXISTS (select 1 from adjustments a
+			where a.matchid = TotalMatchPoints.matchid AND TRIM(a.player) = TRIM(TotalMatchPoints.Player) AND a.field = ` + d.param(2) + `)`
 }
 
-func calculatePointsFinal(db *sql.DB) {
-	updatePointsTableSQL := `UPDATE TotalMatchPoints SET "Total Points"=RunsScored + Boundries + NotOut + Duck + wicket + Maidens + NRR + Extras + Bowled + catch + runouts + MatchWon + OneRunOvers + DropCatches`
-	execQuery(db, updatePointsTableSQL, "Final Point Update Done .... ")
+// roleBonusSQL adds a role bonus to a player's points, taking the points
+// per catch, the wicket type and the player.
+func roleBonusSQL(d sqlDialect) string {
+	return `UPDATE TotalMatchPoints SET KeeperBonus = KeeperBonus + ` + d.param(1) + ` * (
+		select COALESCE(sum(f.catches), 0) from fielders f
+		where f.matchid = TotalMatchPoints.matchid AND TRIM(f.fieldername) = TRIM(TotalMatchPoints.Player) AND f.wicketType = ` + d.param(2) + `)
+		WHERE TRIM(Player) = TRIM(` + d.param(3) + `)`
+}
+
+// totalPointsSQL adds up every points component, adjustments and role
+// bonuses included.
+var totalPointsSQL = `UPDATE TotalMatchPoints SET "Total Points" = ` + strings.Join(pointsComponents, " + ")
+
+func calculatePoints(db *sql.DB) error {
+
+	dropPointsTableSQL := `DROP TABLE IF EXISTS TotalMatchPoints`
+	createPointsTableSQL, err := pointsTableSQL(activeRules, sqliteDialect)
+	if err != nil {
+		return err
+	}
+	if err = execQuery(db, dropPointsTableSQL, "Dropping Points Table"); err != nil {
+		return err
+	}
+	if err = execQuery(db, createPointsTableSQL, "Creating Points Table with this Match details....."); err != nil {
+		return err
+	}
+	if err = execQuery(db, `CREATE INDEX TotalMatchPoints_player ON TotalMatchPoints (Player, matchid)`, "Indexing Points Table"); err != nil {
+		return err
+	}
+	if err = applyAdjustments(db); err != nil {
+		return err
+	}
+	if err = applyRoleBonuses(db); err != nil {
+		return err
+	}
+	return calculatePointsFinal(db)
+}
+
+func calculatePointsFinal(db *sql.DB) error {
+	return execQuery(db, totalPointsSQL, "Final Point Update Done .... ")
 }
 
 func extractRanges(scorecard string) ([]string, []string, []string) {
 
-	phoBowStart, phoBowEnd := calculateRanges(scorecard, "Phoenix Bowling", "Total,")
-	phoBatStart, phoBatEnd := calculateRanges(scorecard, "Phoenix Batting", "Byes:")
-	phoFieldingStart, phoFieldingEnd := calculateRanges(scorecard, opponent+" Batting", "Byes:")
-	return extractRange(scorecard, phoBowStart, phoBowEnd, "Phoenix Bowling", "Total,"), extractRange(scorecard, phoBatStart, phoBatEnd, "Phoenix Batting", "Byes:"), extractRange(scorecard, phoFieldingStart, phoFieldingEnd, opponent+" Batting", "Byes:")
+	section := func(startPattern string, endPattern string) []string {
+		lines, err := readSection(scorecard, startPattern, endPattern)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return lines
+	}
+	return section("Phoenix Bowling", "Total,"), section("Phoenix Batting", "Byes:"), section(opponent+" Batting", "Byes:")
 }
 
-func calculateRanges(scorecard string, startPattern string, endPattern string) (int, int) {
+// readSection reads the lines between the line containing startPattern and
+// the next line containing endPattern.
+func readSection(scorecard string, startPattern string, endPattern string) ([]string, error) {
+	start, end, err := calculateRanges(scorecard, startPattern, endPattern)
+	if err != nil {
+		return nil, err
+	}
+	return extractRange(scorecard, start, end, startPattern, endPattern)
+}
+
+func calculateRanges(scorecard string, startPattern string, endPattern string) (int, int, error) {
 	f0, err := os.Open(scorecard)
-	defer f0.Close()
 	if err != nil {
-		log.Fatal(err)
+		return 0, 0, err
 	}
+	defer f0.Close()
 	var linetext0 string
 	scanner0 := bufio.NewScanner(f0)
 	currentline0 := 0
@@ -707,16 +921,16 @@ func calculateRanges(scorecard string, startPattern string, endPattern string) (
 			break
 		}
 	}
-	return startPosition, endPosition
+	return startPosition, endPosition, scanner0.Err()
 }
 
-func extractRange(scorecard string, startposition int, endposition int, startPattern string, endPattern string) []string {
+func extractRange(scorecard string, startposition int, endposition int, startPattern string, endPattern string) ([]string, error) {
 	var linetext0 string
 	f0, err := os.Open(scorecard)
-	defer f0.Close()
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
+	defer f0.Close()
 	scanner0 := bufio.NewScanner(f0)
 	currentline0 := 0
 	rangevalues := make([]string, 0)
@@ -737,7 +951,76 @@ func extractRange(scorecard string, startposition int, endposition int, startPat
 			break
 		}
 	}
-	return rangevalues
+	return rangevalues, scanner0.Err()
+}
+
+type inningsTotal struct {
+	Team    string `json:"team"`
+	Runs    int    `json:"runs"`
+	Wickets int    `json:"wickets"`
+	Overs   string `json:"overs"`
+	Extras  string `json:"extras"`
+}
+
+// extractInningsTotal reads the "Byes: ..." line that closes a team's batting
+// section, e.g. "Byes: 1 , Leg Byes: 0, Wickets : 5  Wides : 11, No Balls: 0 Penalty : 0,97,20.0"
+func extractInningsTotal(scorecard string, team string) (inningsTotal, error) {
+
+	total := inningsTotal{Team: team}
+	_, endPosition, err := calculateRanges(scorecard, team+" Batting", "Byes:")
+	if err != nil || endPosition == 0 {
+		return total, err
+	}
+
+	f0, err := os.Open(scorecard)
+	if err != nil {
+		return total, err
+	}
+	defer f0.Close()
+	scanner0 := bufio.NewScanner(f0)
+	currentline0 := 0
+	for scanner0.Scan() {
+		currentline0 = currentline0 + 1
+		if currentline0 == endPosition {
+			totalSplits := strings.Split(strings.TrimSpace(scanner0.Text()), ",")
+			if len(totalSplits) < 3 {
+				break
+			}
+			total.Runs, _ = strconv.Atoi(strings.TrimSpace(totalSplits[len(totalSplits)-2]))
+			total.Overs = strings.TrimSpace(totalSplits[len(totalSplits)-1])
+			total.Extras = strings.TrimSpace(strings.Join(totalSplits[:len(totalSplits)-2], ","))
+
+			var re = regexp.MustCompile(`Wickets\s*:\s*(\d+)`)
+			if w := re.FindStringSubmatch(total.Extras); len(w) > 1 {
+				total.Wickets, _ = strconv.Atoi(w[1])
+			}
+			break
+		}
+	}
+	return total, scanner0.Err()
+}
+
+// saveInningsTotals keeps both teams' innings totals for the standings.
+func saveInningsTotals(scorecard string, db *sql.DB) {
+	log.Println("Inserting Innings totals...")
+	insertInningsSQL := `INSERT INTO innings (matchid,team,runs,wickets,overs) VALUES (?,?,?,?,?)`
+	statement, err := db.Prepare(insertInningsSQL) // Prepare statement.
+	// This is good to avoid SQL injections
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+
+	for _, team := range []string{team1, team2} {
+		total, err := extractInningsTotal(scorecard, team)
+		if err != nil {
+			log.Fatal(err)
+		}
+		_, err = statement.Exec(matchid, total.Team, total.Runs, total.Wickets, total.Overs)
+		if err != nil {
+			log.Fatalln(err.Error())
+		}
+	}
+	log.Println("Innings totals inserted ...")
 }
 
 func dumpPointsTableAsCSV() {
@@ -871,7 +1154,9 @@ func replacePlayer(db *sql.DB) {
 			fmt.Println("Please Type Yes/Y/yes/y or No/N/n/no ")
 		}
 	}
-	calculatePointsFinal(db)
+	if err := calculatePointsFinal(db); err != nil {
+		log.Fatalln(err.Error())
+	}
 }
 
 func renderFinalTable(db *sql.DB) {
@@ -913,3 +1198,109 @@ func renderFinalTable(db *sql.DB) {
 	fmt.Println("------------------------------------------")
 	fmt.Println(t.Render())
 }
+
+// colorOutput is whether to colour the output: stdout is a terminal and
+// NO_COLOR (https://no-color.org) is not set.
+func colorOutput() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// pointsComponents are the TotalMatchPoints columns that add up to a player's "Total Points".
+var pointsComponents = []string{"RunsScored", "Boundries", "NotOut", "Duck", "wicket", "Maidens", "NRR", "Extras", "Bowled", "catch", "runouts", "MatchWon", "OneRunOvers", "DropCatches", "KeeperBonus"}
+
+type playerPoints struct {
+	Player     string
+	Components []int
+	Total      int
+}
+
+func getMatchPoints(db *sql.DB, matchid int) ([]playerPoints, error) {
+
+	pointsSQL := `select Player, ` + strings.Join(pointsComponents, ", ") + `, "Total Points" from TotalMatchPoints where matchid = ? order by "Total Points" DESC`
+	row, err := db.Query(pointsSQL, matchid)
+	if err != nil {
+		return nil, err
+	}
+	return scanPlayerPoints(row)
+}
+
+// scanPlayerPoints reads rows of Player, the pointsComponents and "Total Points".
+func scanPlayerPoints(row *sql.Rows) ([]playerPoints, error) {
+	defer row.Close()
+
+	points := make([]playerPoints, 0)
+	for row.Next() {
+		p := playerPoints{Components: make([]int, len(pointsComponents))}
+		dest := []interface{}{&p.Player}
+		for j := range p.Components {
+			dest = append(dest, &p.Components[j])
+		}
+		dest = append(dest, &p.Total)
+		if err := row.Scan(dest...); err != nil {
+			return nil, err
+		}
+		points = append(points, p)
+	}
+	return points, row.Err()
+}
+
+func renderPointsBreakdown(db *sql.DB) {
+
+	t := table.NewWriter()
+
+	rowHeader := table.Row{"S.No", "Player", "Role"}
+	for _, c := range pointsComponents {
+		rowHeader = append(rowHeader, c)
+	}
+	rowHeader = append(rowHeader, "Total Points")
+
+	// Highlight negative points so deductions stand out, on a terminal only
+	color := colorOutput()
+	negativeRed := text.Transformer(func(val interface{}) string {
+		if v, ok := val.(int); ok && v < 0 && color {
+			return text.Colors{text.FgRed}.Sprint(v)
+		}
+		return fmt.Sprint(val)
+	})
+	columnConfigs := make([]table.ColumnConfig, 0)
+	for j := 3; j < len(rowHeader); j++ {
+		columnConfigs = append(columnConfigs, table.ColumnConfig{Number: j + 1, Transformer: negativeRed, TransformerFooter: negativeRed})
+	}
+	t.SetColumnConfigs(columnConfigs)
+
+	totals := make([]int, len(pointsComponents)+1)
+	roles, err := getPlayerRoles(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+	points, err := getMatchPoints(db, matchid)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for i, p := range points {
+		tableRow := table.Row{i + 1, p.Player, roles[strings.TrimSpace(p.Player)].Role}
+		for j := range p.Components {
+			tableRow = append(tableRow, p.Components[j])
+			totals[j] = totals[j] + p.Components[j]
+		}
+		tableRow = append(tableRow, p.Total)
+		totals[len(totals)-1] = totals[len(totals)-1] + p.Total
+		t.AppendRow(tableRow)
+	}
+
+	rowFooter := table.Row{"", "Total", ""}
+	for j := range totals {
+		rowFooter = append(rowFooter, totals[j])
+	}
+
+	t.AppendHeader(rowHeader)
+	t.AppendFooter(rowFooter)
+	fmt.Println("------------------------------------------")
+	fmt.Println("Points Breakdown for this Match")
+	fmt.Println("------------------------------------------")
+	fmt.Println(t.Render())
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"database/sql"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// openTestDB creates a database at the current schema in a temporary directory.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "phoenixPoints.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	CreateTables(db)
+	return db
+}
+
+// importTestScorecards imports the scorecards the way main does, without the
+// prompts, and recalculates the points.
+func importTestScorecards(t *testing.T, db *sql.DB, scorecards ...string) {
+	t.Helper()
+	for _, scorecard := range scorecards {
+		currentMatch = saveMatchDetails(scorecard, db)
+		opponent = getOpponent(db, currentMatch)
+		phoenixBowling, phoenixBatting, phoenixFielding := extractRanges(scorecard)
+		processBatting(phoenixBatting, db)
+		processBowling(phoenixBowling, db)
+		processFielding(phoenixFielding, db)
+	}
+	if err := calculatePoints(db); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// The summary lists exactly the players the points penalise for a duck, a
+// batter not out for 0 after facing a ball included.
+func TestDucksMatchPoints(t *testing.T) {
+	db := openTestDB(t)
+	importTestScorecards(t, db, "scorecard.csv", "Mar3-20.csv")
+	if _, err := db.Exec(`UPDATE batsmen SET Notout = 1 WHERE matchid = 2 AND battername = 'Thenappan Nachiappan'`); err != nil {
+		t.Fatal(err)
+	}
+	if err := calculatePoints(db); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, matchid := range []int{1, 2} {
+		penalised := map[string]bool{}
+		row, err := db.Query(`select TRIM(Player) from TotalMatchPoints where matchid = ? AND Duck != 0`, matchid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for row.Next() {
+			var player string
+			row.Scan(&player)
+			penalised[player] = true
+		}
+		row.Close()
+
+		summary, err := getMatchSummary(db, matchid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ducks := summary.Ducks
+		if len(ducks) != len(penalised) {
+			t.Errorf("match %d : ducks %v, penalised %v", matchid, ducks, penalised)
+		}
+		for _, duck := range ducks {
+			if !penalised[strings.TrimSpace(duck)] {
+				t.Errorf("match %d : %s is a duck in the summary but not in the points", matchid, duck)
+			}
+		}
+		if matchid == 2 && !penalised["Thenappan Nachiappan"] {
+			t.Errorf("match 2 : Thenappan Nachiappan not penalised for 0 not out off 1 ball")
+		}
+	}
+}
+
+type testPoints struct {
+	catch, runouts, bowled, oneRunOvers, dropCatches, total int
+}
+
+func pointsFor(t *testing.T, db *sql.DB, matchid int, player string) testPoints {
+	t.Helper()
+	p := testPoints{}
+	pointsSQL := `select catch, runouts, Bowled, OneRunOvers, DropCatches, "Total Points" from TotalMatchPoints where matchid = ? AND TRIM(Player) = ?`
+	err := db.QueryRow(pointsSQL, matchid, player).Scan(&p.catch, &p.runouts, &p.bowled, &p.oneRunOvers, &p.dropCatches, &p.total)
+	if err != nil {
+		t.Fatalf("points for %s in match %d : %v", player, matchid, err)
+	}
+	return p
+}
+
+// Jai V, Sarpa Vardhan Reddy Nuka, Thenappan Nachiappan and Vikas Sawkar
+// play in both matches, each match's fielding must only count its own.
+func TestFieldingPointsPerMatch(t *testing.T) {
+	db := openTestDB(t)
+	importTestScorecards(t, db, "scorecard.csv", "Mar3-20.csv")
+
+	tests := []struct {
+		matchid                int
+		player                 string
+		catch, runouts, bowled int
+		total                  int
+	}{
+		{1, "Jai V", 16, 0, 2, 77},
+		{2, "Jai V", 0, 0, 0, 27},
+		{1, "Sarpa Vardhan Reddy Nuka", 8, 0, 0, 16},
+		{2, "Sarpa Vardhan Reddy Nuka", 8, 0, 0, 19},
+		{1, "Thenappan Nachiappan", 0, 0, 4, 47},
+		{2, "Thenappan Nachiappan", 0, 0, 0, -8},
+		{1, "Vikas Sawkar", 0, 0, 2, 57},
+		{2, "Vikas Sawkar", 0, 0, 2, 17},
+		{1, "Sriharsha Ganti", 0, 8, 0, 18},
+		{2, "Abhishek Gandhi", 8, 0, 0, 68},
+	}
+	for _, tt := range tests {
+		p := pointsFor(t, db, tt.matchid, tt.player)
+		if p.catch != tt.catch || p.runouts != tt.runouts || p.bowled != tt.bowled || p.total != tt.total {
+			t.Errorf("match %d %s : catch %d runouts %d bowled %d total %d, want %d %d %d %d", tt.matchid, tt.player,
+				p.catch, p.runouts, p.bowled, p.total, tt.catch, tt.runouts, tt.bowled, tt.total)
+		}
+	}
+}
+
+// Every player's fielding points agree with that match's fielders rows.
+func TestFieldingPointsMatchFielders(t *testing.T) {
+	db := openTestDB(t)
+	importTestScorecards(t, db, "scorecard.csv", "Mar3-20.csv")
+
+	checkSQL := `select p.matchid, p.Player, p.catch, p.runouts, p.Bowled,
+		(select COALESCE(sum(f.catches), 0) * 8 from fielders f where f.matchid = p.matchid AND f.fieldername = p.Player),
+		(select COALESCE(sum(CASE WHEN f.wicketType = 'RunOut-DirectHit' THEN f.runouts * 4 ELSE f.runouts * 3 END), 0) from fielders f where f.matchid = p.matchid AND f.fieldername = p.Player),
+		(select COALESCE(sum(f.bowled), 0) * 2 from fielders f where f.matchid = p.matchid AND f.bowlername = p.Player)
+		from TotalMatchPoints p`
+	row, err := db.Query(checkSQL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer row.Close()
+	rows := 0
+	for row.Next() {
+		var matchid int
+		var player string
+		var catch, runouts, bowled, wantCatch, wantRunouts, wantBowled int
+		if err := row.Scan(&matchid, &player, &catch, &runouts, &bowled, &wantCatch, &wantRunouts, &wantBowled); err != nil {
+			t.Fatal(err)
+		}
+		if catch != wantCatch || runouts != wantRunouts || bowled != wantBowled {
+			t.Errorf("match %d %s : catch %d runouts %d bowled %d, want %d %d %d", matchid, player, catch, runouts, bowled, wantCatch, wantRunouts, wantBowled)
+		}
+		rows = rows + 1
+	}
+	if rows != 23 {
+		t.Errorf("%d points rows, want 23", rows)
+	}
+}
+
+// Adjustments entered for the first match survive the recalculation the
+// second import does.
+func TestAdjustmentsSurviveRecalculation(t *testing.T) {
+	db := openTestDB(t)
+	importTestScorecards(t, db, "scorecard.csv")
+	if err := setAdjustment(db, 1, "Jai V", "OneRunOvers", 1, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := setAdjustment(db, 1, "Vikas Sawkar", "DropCatches", 2, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := calculatePointsFinal(db); err != nil {
+		t.Fatal(err)
+	}
+
+	importTestScorecards(t, db, "Mar3-20.csv")
+
+	if p := pointsFor(t, db, 1, "Jai V"); p.oneRunOvers != 5 || p.total != 82 {
+		t.Errorf("match 1 Jai V : OneRunOvers %d total %d, want 5 82", p.oneRunOvers, p.total)
+	}
+	if p := pointsFor(t, db, 1, "Vikas Sawkar"); p.dropCatches != -6 || p.total != 51 {
+		t.Errorf("match 1 Vikas Sawkar : DropCatches %d total %d, want -6 51", p.dropCatches, p.total)
+	}
+	if p := pointsFor(t, db, 2, "Jai V"); p.oneRunOvers != 0 || p.total != 27 {
+		t.Errorf("match 2 Jai V : OneRunOvers %d total %d, want 0 27", p.oneRunOvers, p.total)
+	}
+	if p := pointsFor(t, db, 2, "Vikas Sawkar"); p.dropCatches != 0 || p.total != 17 {
+		t.Errorf("match 2 Vikas Sawkar : DropCatches %d total %d, want 0 17", p.dropCatches, p.total)
+	}
+}
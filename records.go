@@ -0,0 +1,229 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+type clubRecord struct {
+	Record    string `json:"record"`
+	Holder    string `json:"holder"`
+	Value     string `json:"value"`
+	MatchID   int    `json:"matchid"`
+	MatchDate string `json:"matchDate"`
+	Opponent  string `json:"opponent"`
+	rank      []int
+}
+
+// recordQuery finds one club record. The query takes the matches it may
+// use as "matchid IN "+recordMatches and returns matchid, holder, the
+// display values for format and the rank values, best first, bigger rank
+// values being better.
+type recordQuery struct {
+	record string
+	query  string
+	format func(values []int) string
+	ranks  int
+}
+
+// recordMatches selects the matches a record can come from: every match
+// when ?1 is 0, otherwise the matches played before match ?1 and, when ?2
+// is 1, match ?1 itself. Matches on the same day count in matchid order.
+const recordMatches = `(select e.matchid from "match" e LEFT JOIN "match" b ON b.matchid = ?1
+	where ?1 = 0
+		OR substr(e.matchDate, 7, 4) || substr(e.matchDate, 1, 2) || substr(e.matchDate, 4, 2) <
+			substr(b.matchDate, 7, 4) || substr(b.matchDate, 1, 2) || substr(b.matchDate, 4, 2)
+		OR (e.matchDate = b.matchDate AND e.matchid < b.matchid)
+		OR (?2 = 1 AND e.matchid = b.matchid))`
+
+var recordQueries = []recordQuery{
+	{
+		record: "Highest Individual Score",
+		query: `select matchid, TRIM(battername), runs, balls, Notout, runs, -balls from batsmen
+			where matchid IN ` + recordMatches + ` AND (runs > 0 OR balls > 0) order by runs DESC, balls ASC, matchid LIMIT 1`,
+		format: func(v []int) string {
+			notout := ""
+			if v[2] == 1 {
+				notout = "*"
+			}
+			return fmt.Sprintf("%d%s (%d balls)", v[0], notout, v[1])
+		},
+		ranks: 2,
+	},
+	{
+		// the scorecards do not say on which ball a batter reached fifty,
+		// so the fastest fifty is the fewest balls faced in a 50+ innings
+		record: "Fastest Fifty",
+		query: `select matchid, TRIM(battername), runs, balls, Notout, -balls, runs from batsmen
+			where matchid IN ` + recordMatches + ` AND runs >= 50 AND balls > 0 order by balls ASC, runs DESC, matchid LIMIT 1`,
+		format: func(v []int) string {
+			notout := ""
+			if v[2] == 1 {
+				notout = "*"
+			}
+			return fmt.Sprintf("%d%s off %d balls", v[0], notout, v[1])
+		},
+		ranks: 2,
+	},
+	{
+		record: "Best Bowling Figures",
+		query: `select matchid, TRIM(bowlerName), wickets, RunsGiven, wickets, -RunsGiven from bowlers
+			where matchid IN ` + recordMatches + ` AND CAST(overs AS REAL) > 0 order by wickets DESC, RunsGiven ASC, matchid LIMIT 1`,
+		format: func(v []int) string { return fmt.Sprintf("%d/%d", v[0], v[1]) },
+		ranks:  2,
+	},
+	{
+		record: "Most Catches in a Match",
+		query: `select matchid, TRIM(fieldername), sum(catches), sum(catches) from fielders
+			where matchid IN ` + recordMatches + ` AND catches > 0 group by matchid, TRIM(fieldername) order by sum(catches) DESC, matchid LIMIT 1`,
+		format: func(v []int) string { return fmt.Sprintf("%d catches", v[0]) },
+		ranks:  1,
+	},
+	{
+		record: "Highest Team Total",
+		query: `select matchid, team, runs, wickets, runs from innings
+			where matchid IN ` + recordMatches + ` AND team = 'Phoenix' order by runs DESC, matchid LIMIT 1`,
+		format: func(v []int) string { return fmt.Sprintf("%d/%d", v[0], v[1]) },
+		ranks:  1,
+	},
+	{
+		// Phoenix defended a total when they won by runs
+		record: "Lowest Total Defended",
+		query: `select i.matchid, i.team, i.runs, i.wickets, -i.runs from innings i JOIN "match" m ON i.matchid = m.matchid
+			where i.matchid IN ` + recordMatches + ` AND i.team = 'Phoenix' AND m.Result LIKE 'Phoenix won by % Run%'
+			order by i.runs ASC, i.matchid LIMIT 1`,
+		format: func(v []int) string { return fmt.Sprintf("%d/%d", v[0], v[1]) },
+		ranks:  1,
+	},
+	{
+		record: "Highest Fantasy Score",
+		query: `select matchid, TRIM(Player), "Total Points", "Total Points" from TotalMatchPoints
+			where matchid IN ` + recordMatches + ` order by "Total Points" DESC, matchid LIMIT 1`,
+		format: func(v []int) string { return fmt.Sprintf("%d points", v[0]) },
+		ranks:  1,
+	},
+}
+
+// getRecords returns the club records over every match. A record with no
+// data yet is left out.
+func getRecords(db *sql.DB) ([]clubRecord, error) {
+	return getRecordsAt(db, 0, true)
+}
+
+// getRecordsAt returns the club records as they stood when match matchid
+// was played, over the matches before it and, with including, the match
+// itself. A matchid of 0 uses every match.
+func getRecordsAt(db *sql.DB, matchid int, including bool) ([]clubRecord, error) {
+
+	include := 0
+	if including {
+		include = 1
+	}
+	records := make([]clubRecord, 0)
+	for _, q := range recordQueries {
+		row, err := db.Query(q.query, matchid, include)
+		if err != nil {
+			return nil, err
+		}
+		cols, err := row.Columns()
+		if err != nil {
+			row.Close()
+			return nil, err
+		}
+		if !row.Next() {
+			row.Close()
+			if err = row.Err(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		r := clubRecord{Record: q.record}
+		values := make([]int, len(cols)-2)
+		dest := []interface{}{&r.MatchID, &r.Holder}
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+		err = row.Scan(dest...)
+		row.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		r.rank = values[len(values)-q.ranks:]
+		r.Value = q.format(values[:len(values)-q.ranks])
+		m, err := getMatchDetails(db, r.MatchID)
+		if err != nil {
+			return nil, err
+		}
+		r.MatchDate = m.MatchDate
+		r.Opponent = m.Team1
+		if m.Team1 == "Phoenix" {
+			r.Opponent = m.Team2
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+// getBrokenRecords lists the records a match set by beating the record
+// from the matches played before it. The first record of its kind is not
+// counted.
+func getBrokenRecords(db *sql.DB, matchid int) ([]clubRecord, error) {
+
+	others, err := getRecordsAt(db, matchid, false)
+	if err != nil {
+		return nil, err
+	}
+	previous := make(map[string]clubRecord)
+	for _, r := range others {
+		previous[r.Record] = r
+	}
+	current, err := getRecordsAt(db, matchid, true)
+	if err != nil {
+		return nil, err
+	}
+	broken := make([]clubRecord, 0)
+	for _, r := range current {
+		old, ok := previous[r.Record]
+		if ok && r.MatchID == matchid && betterRank(r.rank, old.rank) {
+			broken = append(broken, r)
+		}
+	}
+	return broken, nil
+}
+
+func betterRank(a []int, b []int) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] > b[i]
+		}
+	}
+	return false
+}
+
+// announceRecords logs every record broken by the imported match.
+func announceRecords(db *sql.DB, matchid int) error {
+	broken, err := getBrokenRecords(db, matchid)
+	if err != nil {
+		return err
+	}
+	for _, r := range broken {
+		log.Println("New club record! " + r.Record + " : " + r.Holder + " " + r.Value)
+	}
+	return nil
+}
+
+func renderRecords(records []clubRecord) {
+	t := table.NewWriter()
+	t.AppendHeader(table.Row{"Record", "Holder", "Value", "Opponent", "Match Date", "Match ID"})
+	for _, r := range records {
+		t.AppendRow(table.Row{r.Record, r.Holder, r.Value, r.Opponent, r.MatchDate, r.MatchID})
+	}
+	fmt.Println("------------------------------------------")
+	fmt.Println("Club Records")
+	fmt.Println("------------------------------------------")
+	fmt.Println(t.Render())
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"testing"
+)
+
+func recordNames(records []clubRecord) map[string]clubRecord {
+	names := make(map[string]clubRecord)
+	for _, r := range records {
+		names[r.Record] = r
+	}
+	return names
+}
+
+// A match only breaks the records of the matches played before it, by date
+// and then by matchid on the same day, whatever order they were imported in.
+func TestBrokenRecordsOnlyEarlierMatches(t *testing.T) {
+	db := openTestDB(t)
+	importTestScorecards(t, db, "scorecard.csv", "Mar3-20.csv")
+
+	broken := func(matchid int) map[string]clubRecord {
+		t.Helper()
+		records, err := getBrokenRecords(db, matchid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return recordNames(records)
+	}
+	setDate := func(matchid int, matchDate string) {
+		t.Helper()
+		if _, err := db.Exec(`UPDATE "match" SET matchDate = ? WHERE matchid = ?`, matchDate, matchid); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// match 1 on 03/05/2022 is the first match, it has nothing to break
+	if got := broken(1); len(got) != 0 {
+		t.Errorf("first match broke %v, want none", got)
+	}
+	// played after match 2 its 44 beats the highest score before it
+	setDate(1, "04/01/2022")
+	if _, ok := broken(1)["Highest Individual Score"]; !ok {
+		t.Errorf("match 1 played last did not break the highest individual score")
+	}
+	if got := broken(2); len(got) != 0 {
+		t.Errorf("match 2 played first broke %v, want none", got)
+	}
+	// on the same day match 1 is the earlier one
+	setDate(2, "04/01/2022")
+	if got := broken(1); len(got) != 0 {
+		t.Errorf("match 1 of a double-header broke %v, want none", got)
+	}
+}
+
+// The fastest fifty is the fewest balls faced in an innings of 50 or more.
+func TestFastestFifty(t *testing.T) {
+	db := openTestDB(t)
+	importTestScorecards(t, db, "scorecard.csv", "Mar3-20.csv")
+
+	fifty := func(matchid int, runs int, balls int) {
+		t.Helper()
+		_, err := db.Exec(`UPDATE batsmen SET runs = ?, balls = ? WHERE matchid = ? AND TRIM(battername) = 'Arvind Kannan'`, runs, balls, matchid)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	fifty(1, 52, 30)
+	fifty(2, 50, 28)
+
+	records, err := getRecords(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, ok := recordNames(records)["Fastest Fifty"]
+	if !ok || r.MatchID != 2 || r.Value != "50 off 28 balls" {
+		t.Errorf("fastest fifty %+v, want 50 off 28 balls in match 2", r)
+	}
+	broken, err := getBrokenRecords(db, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := recordNames(broken)["Fastest Fifty"]; !ok {
+		t.Errorf("match 2 did not break the fastest fifty, broke %+v", broken)
+	}
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"database/sql"
+	"html/template"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type battingLine struct {
+	Batter string `json:"batter"`
+	HowOut string `json:"howOut"`
+	Runs   string `json:"runs"`
+	Balls  string `json:"balls"`
+	Fours  string `json:"fours"`
+	Sixers string `json:"sixers"`
+}
+
+type bowlingLine struct {
+	Bowler  string `json:"bowler"`
+	Overs   string `json:"overs"`
+	Maidens string `json:"maidens"`
+	Runs    string `json:"runs"`
+	Wickets string `json:"wickets"`
+	Wides   string `json:"wides"`
+	NoBalls string `json:"noBalls"`
+}
+
+type inningsCard struct {
+	Team    string        `json:"team"`
+	Batting []battingLine `json:"batting"`
+	Bowling []bowlingLine `json:"bowling"`
+	Total   inningsTotal  `json:"total"`
+}
+
+type matchReport struct {
+	Match         matchDetails
+	Innings       []inningsCard
+	Components    []string
+	Points        []playerPoints
+	TopPerformers []playerPoints
+}
+
+// writeHTMLReport writes a self-contained report_<matchid>.html for the
+// current match, next to the points_<matchid>.csv file.
+func writeHTMLReport(db *sql.DB, scorecard string) error {
+
+	var err error
+	report := matchReport{Components: pointsComponents}
+	if report.Match, err = getMatchDetails(db, matchid); err != nil {
+		return err
+	}
+	if report.Points, err = getMatchPoints(db, matchid); err != nil {
+		return err
+	}
+	report.TopPerformers = report.Points
+	if len(report.TopPerformers) > 3 {
+		report.TopPerformers = report.TopPerformers[:3]
+	}
+
+	if report.Innings, err = extractInnings(scorecard, report.Match.Team1, report.Match.Team2); err != nil {
+		return err
+	}
+
+	reportTemplate := template.Must(template.New("report").Funcs(template.FuncMap{
+		"inc":      func(i int) int { return i + 1 },
+		"negative": func(i int) bool { return i < 0 },
+	}).Parse(reportHTML))
+
+	reportFile := "report_" + strconv.Itoa(matchid) + ".html"
+	f, err := os.Create(reportFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	err = reportTemplate.Execute(f, report)
+	if err != nil {
+		return err
+	}
+	log.Println("HTML report : " + reportFile + " created ")
+	return nil
+}
+
+// extractInnings returns both innings in the order they appear on the scorecard.
+func extractInnings(scorecard string, team1 string, team2 string) ([]inningsCard, error) {
+
+	team1Start, _, err := calculateRanges(scorecard, team1+" Batting", "Byes:")
+	if err != nil {
+		return nil, err
+	}
+	team2Start, _, err := calculateRanges(scorecard, team2+" Batting", "Byes:")
+	if err != nil {
+		return nil, err
+	}
+	if team2Start < team1Start {
+		team1, team2 = team2, team1
+	}
+	innings := make([]inningsCard, 0, 2)
+	for _, teams := range [][2]string{{team1, team2}, {team2, team1}} {
+		card, err := extractInningsCard(scorecard, teams[0], teams[1])
+		if err != nil {
+			return nil, err
+		}
+		innings = append(innings, card)
+	}
+	return innings, nil
+}
+
+// extractInningsCard pulls a batting side's scorecard and the fielding
+// side's bowling figures for one innings.
+func extractInningsCard(scorecard string, battingTeam string, bowlingTeam string) (inningsCard, error) {
+
+	card := inningsCard{Team: battingTeam}
+	total, err := extractInningsTotal(scorecard, battingTeam)
+	if err != nil {
+		return card, err
+	}
+	card.Total = total
+
+	batting, err := readSection(scorecard, battingTeam+" Batting", "Byes:")
+	if err != nil {
+		return card, err
+	}
+	for _, line := range batting {
+		battingSplits := strings.Split(line, ",")
+		if battingSplits[0] == "BatsMan" || len(battingSplits) < 8 {
+			continue
+		}
+		card.Batting = append(card.Batting, battingLine{
+			Batter: battingSplits[0],
+			HowOut: describeDismissal(battingSplits[1], battingSplits[2], battingSplits[3], battingSplits[5]),
+			Runs:   battingSplits[4],
+			Balls:  battingSplits[5],
+			Fours:  battingSplits[6],
+			Sixers: battingSplits[7],
+		})
+	}
+
+	bowling, err := readSection(scorecard, bowlingTeam+" Bowling", "Total,")
+	if err != nil {
+		return card, err
+	}
+	for _, line := range bowling {
+		bowlingSplits := strings.Split(line, ",")
+		if bowlingSplits[0] == "Bowler" || len(bowlingSplits) < 7 {
+			continue
+		}
+		card.Bowling = append(card.Bowling, bowlingLine{
+			Bowler:  bowlingSplits[0],
+			Overs:   bowlingSplits[1],
+			Maidens: bowlingSplits[2],
+			Runs:    bowlingSplits[3],
+			Wickets: bowlingSplits[4],
+			Wides:   bowlingSplits[5],
+			NoBalls: bowlingSplits[6],
+		})
+	}
+	return card, nil
+}
+
+// describeDismissal turns the scorecard's "How Out" codes into the usual
+// scorecard wording, e.g. "ct,Jai V,Kumaresan K" becomes "c Jai V b Kumaresan K".
+func describeDismissal(howOut string, fielder string, bowler string, balls string) string {
+	switch strings.TrimSpace(howOut) {
+	case "ct":
+		if fielder == bowler {
+			return "c & b " + bowler
+		}
+		return "c " + fielder + " b " + bowler
+	case "ctw":
+		return "c †" + fielder + " b " + bowler
+	case "b":
+		return "b " + bowler
+	case "ro":
+		if strings.TrimSpace(fielder) == "" {
+			return "run out (" + bowler + ")"
+		}
+		return "run out (" + fielder + "/" + bowler + ")"
+	case "":
+		if ballsFaced, _ := strconv.Atoi(balls); ballsFaced > 0 {
+			return "not out"
+		}
+		return "did not bat"
+	}
+	return howOut
+}
+
+const reportHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Match.Team1}} vs {{.Match.Team2}} - {{.Match.MatchDate}}</title>
+<style>
+body { font-family: Arial, Helvetica, sans-serif; margin: 1.5em; color: #222; }
+h1 { margin-bottom: 0.2em; }
+.meta { color: #555; margin-top: 0; }
+.result { font-size: 1.2em; font-weight: bold; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; }
+th { background: #f0f0f0; }
+td.num { text-align: right; }
+td.neg { text-align: right; color: #c00; }
+tfoot td { font-weight: bold; }
+.top { display: inline-block; border: 1px solid #ccc; border-radius: 6px; padding: 0.6em 1em; margin-right: 1em; }
+.top .points { font-size: 1.6em; font-weight: bold; }
+</style>
+</head>
+<body>
+<h1>{{.Match.Team1}} vs {{.Match.Team2}}</h1>
+<p class="meta">{{.Match.Series}} &middot; {{.Match.Division}} &middot; {{.Match.Stage}} &middot; {{.Match.MatchDate}} &middot; Match ID {{.Match.MatchID}}</p>
+<p class="result">{{.Match.Result}}</p>
+
+<h2>Top Performers</h2>
+{{range $i, $p := .TopPerformers}}<div class="top"><div>#{{inc $i}} {{$p.Player}}</div><div class="points">{{$p.Total}}</div></div>
+{{end}}
+
+{{range .Innings}}
+<h2>{{.Team}} Batting &ndash; {{.Total.Runs}}/{{.Total.Wickets}} ({{.Total.Overs}} overs)</h2>
+<table>
+<thead><tr><th>Batter</th><th></th><th>R</th><th>B</th><th>4s</th><th>6s</th></tr></thead>
+<tbody>
+{{range .Batting}}<tr><td>{{.Batter}}</td><td>{{.HowOut}}</td><td class="num">{{.Runs}}</td><td class="num">{{.Balls}}</td><td class="num">{{.Fours}}</td><td class="num">{{.Sixers}}</td></tr>
+{{end}}</tbody>
+<tfoot><tr><td colspan="6">{{.Total.Extras}}</td></tr></tfoot>
+</table>
+<table>
+<thead><tr><th>Bowler</th><th>O</th><th>M</th><th>R</th><th>W</th><th>Wd</th><th>NB</th></tr></thead>
+<tbody>
+{{range .Bowling}}<tr><td>{{.Bowler}}</td><td class="num">{{.Overs}}</td><td class="num">{{.Maidens}}</td><td class="num">{{.Runs}}</td><td class="num">{{.Wickets}}</td><td class="num">{{.Wides}}</td><td class="num">{{.NoBalls}}</td></tr>
+{{end}}</tbody>
+</table>
+{{end}}
+
+<h2>Points Breakdown</h2>
+<table>
+<thead><tr><th>S.No</th><th>Player</th>{{range .Components}}<th>{{.}}</th>{{end}}<th>Total Points</th></tr></thead>
+<tbody>
+{{range $i, $p := .Points}}<tr><td class="num">{{inc $i}}</td><td>{{$p.Player}}</td>{{range $p.Components}}<td class="{{if negative .}}neg{{else}}num{{end}}">{{.}}</td>{{end}}<td class="{{if negative $p.Total}}neg{{else}}num{{end}}">{{$p.Total}}</td></tr>
+{{end}}</tbody>
+</table>
+</body>
+</html>
+`
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+const (
+	roleBatter     = "Batter"
+	roleBowler     = "Bowler"
+	roleAllRounder = "All-Rounder"
+	roleKeeper     = "Wicket-Keeper"
+)
+
+// Role inference from history: a player who has taken a catch behind the
+// stumps keeps wicket, one who has bowled in at least roleRegularShare of
+// their matches is a bowler, and a bowler who also averages
+// roleAllRounderRuns runs per innings batted is an all-rounder.
+const (
+	roleRegularShare   = 0.5
+	roleAllRounderRuns = 15.0
+)
+
+type playerRole struct {
+	Player string `json:"player"`
+	Role   string `json:"role"`
+	Source string `json:"source"`
+}
+
+// roleNames maps the names accepted when setting a role to the role.
+var roleNames = map[string]string{
+	"batter":        roleBatter,
+	"batsman":       roleBatter,
+	"bowler":        roleBowler,
+	"all-rounder":   roleAllRounder,
+	"allrounder":    roleAllRounder,
+	"wicket-keeper": roleKeeper,
+	"wicketkeeper":  roleKeeper,
+	"keeper":        roleKeeper,
+}
+
+// getPlayerRoles returns every player's role, the manually set role when
+// there is one and otherwise the role inferred from their matches. Its SQL
+// runs on SQLite and PostgreSQL alike.
+func getPlayerRoles(db *sql.DB) (map[string]playerRole, error) {
+
+	type history struct {
+		matches, bowled, innings, runs, keeperDismissals int
+	}
+	players := make(map[string]*history)
+
+	battingSQL := `select TRIM(battername), count(*), sum(CASE WHEN balls > 0 OR runs > 0 THEN 1 ELSE 0 END), sum(runs) from batsmen group by TRIM(battername)`
+	err := queryRows(db, battingSQL, nil, func(row *sql.Rows) error {
+		var player string
+		h := history{}
+		if err := row.Scan(&player, &h.matches, &h.innings, &h.runs); err != nil {
+			return err
+		}
+		players[player] = &h
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	bowlingSQL := `select TRIM(bowlerName), count(distinct matchid) from bowlers where CAST(NULLIF(overs, '') AS REAL) > 0 group by TRIM(bowlerName)`
+	err = queryRows(db, bowlingSQL, nil, func(row *sql.Rows) error {
+		var player string
+		var bowled int
+		if err := row.Scan(&player, &bowled); err != nil {
+			return err
+		}
+		if h, ok := players[player]; ok {
+			h.bowled = bowled
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	keeperSQL := `select TRIM(fieldername), sum(catches) from fielders where wicketType = 'CaughtBehind' group by TRIM(fieldername)`
+	err = queryRows(db, keeperSQL, nil, func(row *sql.Rows) error {
+		var player string
+		var dismissals int
+		if err := row.Scan(&player, &dismissals); err != nil {
+			return err
+		}
+		if h, ok := players[player]; ok {
+			h.keeperDismissals = dismissals
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make(map[string]playerRole)
+	for player, h := range players {
+		role := roleBatter
+		switch {
+		case h.keeperDismissals > 0:
+			role = roleKeeper
+		case float64(h.bowled) >= roleRegularShare*float64(h.matches):
+			role = roleBowler
+			if h.innings > 0 && float64(h.runs)/float64(h.innings) >= roleAllRounderRuns {
+				role = roleAllRounder
+			}
+		}
+		roles[player] = playerRole{Player: player, Role: role, Source: "inferred"}
+	}
+
+	err = queryRows(db, "select TRIM(player), role from player_roles", nil, func(row *sql.Rows) error {
+		r := playerRole{Source: "manual"}
+		if err := row.Scan(&r.Player, &r.Role); err != nil {
+			return err
+		}
+		roles[r.Player] = r
+		return nil
+	})
+	return roles, err
+}
+
+// getPlayerRole returns one player's role, Batter for a player with no matches yet.
+func getPlayerRole(db *sql.DB, playerName string) (string, error) {
+	roles, err := getPlayerRoles(db)
+	if err != nil {
+		return "", err
+	}
+	if r, ok := roles[strings.TrimSpace(playerName)]; ok {
+		return r.Role, nil
+	}
+	return roleBatter, nil
+}
+
+// setPlayerRole sets a player's role manually, role "auto" goes back to the
+// inferred role. Role bonuses are recalculated for every match.
+func setPlayerRole(db *sql.DB, playerName string, role string, reason string) error {
+	if strings.TrimSpace(playerName) == "" {
+		return invalidError("player name is required")
+	}
+	name := "auto"
+	if strings.ToLower(strings.TrimSpace(role)) != "auto" {
+		var ok bool
+		name, ok = roleNames[strings.ToLower(strings.TrimSpace(role))]
+		if !ok {
+			return invalidError(role + " is not a role, use batter, bowler, all-rounder, wicket-keeper or auto")
+		}
+	}
+	if _, err := storePlayerRole(db, playerName, name, reason); err != nil {
+		return err
+	}
+	if err := applyRoleBonuses(db); err != nil {
+		return err
+	}
+	return calculatePointsFinal(db)
+}
+
+// storePlayerRole keeps a player's role, "auto" or one of roleNames, and
+// logs a changed role in the audit log, returning its audit ID, 0 when the
+// role did not change. Role bonuses are left for the caller to recalculate.
+func storePlayerRole(db dbExecer, playerName string, name string, reason string) (int64, error) {
+	old, err := manualRole(db, playerName)
+	if err != nil {
+		return 0, err
+	}
+	_, err = db.Exec("DELETE FROM player_roles WHERE TRIM(player) = TRIM(?)", playerName)
+	if err != nil {
+		return 0, err
+	}
+	if name != "auto" {
+		insertRoleSQL := `INSERT INTO player_roles (player,role,enteredBy,enteredAt) VALUES (TRIM(?),?,?,?)`
+		_, err = db.Exec(insertRoleSQL, playerName, name, currentUser(), time.Now().Format("2006-01-02 15:04:05"))
+		if err != nil {
+			return 0, err
+		}
+	}
+	if old == name {
+		return 0, nil
+	}
+	return recordAudit(db, auditEntry{Kind: auditRole, Player: playerName, Field: "role", OldValue: old, NewValue: name, Reason: reason})
+}
+
+// roleBonus is the extra points a player whose role is Role gets per catch
+// of the WicketType kind.
+type roleBonus struct {
+	Role       string
+	WicketType string
+	Label      string
+	Points     int
+}
+
+// roleBonuses are the role bonuses under rules, the one list both the
+// KeeperBonus column and explain are worked out from.
+func roleBonuses(rules pointsRules) []roleBonus {
+	return []roleBonus{
+		{Role: roleKeeper, WicketType: "CaughtBehind", Label: "keeper dismissal", Points: rules.KeeperDismissal},
+	}
+}
+
+// applyRoleBonuses fills the KeeperBonus column from roleBonuses for the
+// players with each bonus's role.
+func applyRoleBonuses(db *sql.DB) error {
+
+	if err := execQuery(db, `UPDATE TotalMatchPoints SET KeeperBonus = 0`, "Clearing Role Bonuses"); err != nil {
+		return err
+	}
+
+	roles, err := getPlayerRoles(db)
+	if err != nil {
+		return err
+	}
+	statement, err := db.Prepare(roleBonusSQL(sqliteDialect))
+	if err != nil {
+		return err
+	}
+	defer statement.Close()
+	for _, bonus := range roleBonuses(activeRules) {
+		for _, r := range roles {
+			if r.Role != bonus.Role {
+				continue
+			}
+			if _, err = statement.Exec(bonus.Points, bonus.WicketType, r.Player); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func sortedPlayerRoles(roles map[string]playerRole) []playerRole {
+	sorted := make([]playerRole, 0)
+	for _, r := range roles {
+		sorted = append(sorted, r)
+	}
+	sort.Slice(sorted, func(a, b int) bool {
+		if sorted[a].Role != sorted[b].Role {
+			return sorted[a].Role < sorted[b].Role
+		}
+		return sorted[a].Player < sorted[b].Player
+	})
+	return sorted
+}
+
+func renderPlayerRoles(roles []playerRole) {
+	t := table.NewWriter()
+	t.AppendHeader(table.Row{"S.No", "Player", "Role", "Source"})
+	for i, r := range roles {
+		t.AppendRow(table.Row{i + 1, r.Player, r.Role, r.Source})
+	}
+	fmt.Println("------------------------------------------")
+	fmt.Println("Player Roles")
+	fmt.Println("------------------------------------------")
+	fmt.Println(t.Render())
+}
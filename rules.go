@@ -0,0 +1,58 @@
+package main
+
+// pointsRules holds the fantasy point values applied to each scoring event.
+// The points SQL, the manual adjustments and explain all read activeRules,
+// so a rule is changed here only.
+type pointsRules struct {
+	Run             int     // per run scored
+	Four            int     // per four hit
+	NotOut          int     // batter not out at the end of the innings
+	Duck            int     // 0 after facing a ball, out or not, see duckSQL
+	Wicket          int     // per wicket taken
+	Maiden          int     // per maiden over
+	GoodEconomy     int     // economy at or below GoodEconomyMax
+	GoodEconomyMax  float64 // runs per over
+	PoorEconomy     int     // economy at or above PoorEconomyMin
+	PoorEconomyMin  float64 // runs per over
+	Extra           int     // per wide or no ball bowled
+	NoExtras        int     // bowled without a wide or no ball
+	Bowled          int     // per batter bowled
+	Catch           int     // per catch, including caught behind and caught & bowled
+	RunOut          int     // per fielder involved in a run out
+	DirectHit       int     // run out with a direct hit
+	MatchWon        int     // every player in a winning Phoenix side
+	OneRunOver      int     // per over conceding a single run (entered manually)
+	DropCatch       int     // per dropped catch (entered manually)
+	KeeperDismissal int     // per catch behind the stumps by a Wicket-Keeper, on top of Catch
+}
+
+var activeRules = pointsRules{
+	Run:             2,
+	Four:            5,
+	NotOut:          2,
+	Duck:            -3,
+	Wicket:          10,
+	Maiden:          5,
+	GoodEconomy:     5,
+	GoodEconomyMax:  5,
+	PoorEconomy:     -3,
+	PoorEconomyMin:  7,
+	Extra:           -2,
+	NoExtras:        3,
+	Bowled:          2,
+	Catch:           8,
+	RunOut:          3,
+	DirectHit:       4,
+	MatchWon:        10,
+	OneRunOver:      5,
+	DropCatch:       -3,
+	KeeperDismissal: 2,
+}
+
+// duckSQL is the condition on batsmen b for a duck: 0 after facing a ball,
+// out or not. isDuck is the same test in Go.
+const duckSQL = `b.runs = 0 AND b.balls > 0`
+
+func isDuck(runs int, balls int) bool {
+	return runs == 0 && balls > 0
+}
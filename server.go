@@ -0,0 +1,547 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// importMutex serialises imports and corrections; the import pipeline keeps
+// the current match in package variables.
+var importMutex sync.Mutex
+
+type pointsResponse struct {
+	Player     string         `json:"player"`
+	Role       string         `json:"role"`
+	Components map[string]int `json:"components"`
+	Total      int            `json:"total"`
+}
+
+type matchResponse struct {
+	Match  matchDetails     `json:"match"`
+	Points []pointsResponse `json:"points"`
+}
+
+type previewResponse struct {
+	Match   matchDetails  `json:"match"`
+	Innings []inningsCard `json:"innings"`
+}
+
+type renameRequest struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Reason string `json:"reason"`
+}
+
+type adjustmentRequest struct {
+	Player string `json:"player"`
+	Field  string `json:"field"`
+	Value  int    `json:"value"`
+	Reason string `json:"reason"`
+}
+
+type roleRequest struct {
+	Player string `json:"player"`
+	Role   string `json:"role"`
+	Reason string `json:"reason"`
+}
+
+type playerResponse struct {
+	Player  string              `json:"player"`
+	Role    string              `json:"role"`
+	Matches int                 `json:"matches"`
+	Points  int                 `json:"points"`
+	History []playerMatchPoints `json:"history"`
+	Career  seasonStats         `json:"career"`
+	Seasons []seasonStats       `json:"seasons"`
+}
+
+// serve runs the JSON API over the store until the process is stopped. The
+// corrections, audit, fantasy and statistics endpoints need phoenixPoints.db.
+func serve(store Store, addr string) {
+	mux := http.NewServeMux()
+	registerStoreAPI(mux, store)
+	if s, ok := store.(*sqliteStore); ok {
+		// SQLite allows a single writer, keep every request on one connection
+		s.db.SetMaxOpenConns(1)
+		registerAPI(mux, s.db)
+	}
+	registerWebUI(mux)
+
+	log.Println("Serving phoenixPoints API on " + addr)
+	log.Fatal(http.ListenAndServe(addr, mux))
+}
+
+// registerStoreAPI adds the matches, points, leaderboard and players
+// endpoints, which work on either store.
+func registerStoreAPI(mux *http.ServeMux, store Store) {
+
+	mux.HandleFunc("GET /api/matches", func(w http.ResponseWriter, r *http.Request) {
+		matches, err := store.Matches()
+		writeJSONResult(w, http.StatusOK, matches, err)
+	})
+
+	mux.HandleFunc("GET /api/matches/{id}", func(w http.ResponseWriter, r *http.Request) {
+		m, ok := matchParam(w, r, store)
+		if !ok {
+			return
+		}
+		points, err := getPointsResponse(store, m.MatchID)
+		writeJSONResult(w, http.StatusOK, matchResponse{Match: m, Points: points}, err)
+	})
+
+	mux.HandleFunc("GET /api/matches/{id}/points", func(w http.ResponseWriter, r *http.Request) {
+		m, ok := matchParam(w, r, store)
+		if !ok {
+			return
+		}
+		points, err := getPointsResponse(store, m.MatchID)
+		writeJSONResult(w, http.StatusOK, points, err)
+	})
+
+	mux.HandleFunc("GET /api/matches/{id}/scorecard", func(w http.ResponseWriter, r *http.Request) {
+		m, ok := matchParam(w, r, store)
+		if !ok {
+			return
+		}
+		scorecard, err := store.Scorecard(m.MatchID)
+		writeJSONResult(w, http.StatusOK, scorecard, err)
+	})
+
+	mux.HandleFunc("POST /api/matches", func(w http.ResponseWriter, r *http.Request) {
+		id, err := importUploadedScorecard(store, r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		m, err := store.Match(id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		points, err := getPointsResponse(store, id)
+		writeJSONResult(w, http.StatusCreated, matchResponse{Match: m, Points: points}, err)
+	})
+
+	mux.HandleFunc("POST /api/scorecards/preview", func(w http.ResponseWriter, r *http.Request) {
+		preview, err := previewUploadedScorecard(r)
+		writeJSONResult(w, http.StatusOK, preview, err)
+	})
+
+	mux.HandleFunc("GET /api/leaderboard", func(w http.ResponseWriter, r *http.Request) {
+		leaderboard, err := store.Leaderboard(r.URL.Query().Get("series"))
+		writeJSONResult(w, http.StatusOK, leaderboard, err)
+	})
+
+	mux.HandleFunc("GET /api/players", func(w http.ResponseWriter, r *http.Request) {
+		players, err := store.Players()
+		writeJSONResult(w, http.StatusOK, players, err)
+	})
+}
+
+// registerAPI adds the endpoints that work on phoenixPoints.db only.
+func registerAPI(mux *http.ServeMux, db *sql.DB) {
+	store := &sqliteStore{db: db}
+
+	mux.HandleFunc("POST /api/matches/{id}/renames", func(w http.ResponseWriter, r *http.Request) {
+		id, ok := matchIDParam(w, r, store)
+		if !ok {
+			return
+		}
+		rename := renameRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&rename); err != nil || rename.From == "" || rename.To == "" {
+			writeJSONError(w, http.StatusBadRequest, "expected {\"from\": \"name\", \"to\": \"name\"}")
+			return
+		}
+		importMutex.Lock()
+		defer importMutex.Unlock()
+		if _, err := replacePlayerName(db, id, rename.From, rename.To, rename.Reason); err != nil {
+			writeError(w, err)
+			return
+		}
+		points, err := getPointsResponse(store, id)
+		writeJSONResult(w, http.StatusOK, points, err)
+	})
+
+	mux.HandleFunc("POST /api/matches/{id}/adjustments", func(w http.ResponseWriter, r *http.Request) {
+		id, ok := matchIDParam(w, r, store)
+		if !ok {
+			return
+		}
+		adjustment := adjustmentRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&adjustment); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "expected {\"player\": \"name\", \"field\": \"OneRunOvers\", \"value\": 1}")
+			return
+		}
+		importMutex.Lock()
+		defer importMutex.Unlock()
+		err := setAdjustment(db, id, adjustment.Player, adjustment.Field, adjustment.Value, adjustment.Reason)
+		if err == nil {
+			err = calculatePointsFinal(db)
+		}
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		points, err := getPointsResponse(store, id)
+		writeJSONResult(w, http.StatusOK, points, err)
+	})
+
+	mux.HandleFunc("GET /api/matches/{id}/audit", func(w http.ResponseWriter, r *http.Request) {
+		id, ok := matchIDParam(w, r, store)
+		if !ok {
+			return
+		}
+		entries, err := getAuditLog(db, id)
+		writeJSONResult(w, http.StatusOK, entries, err)
+	})
+
+	mux.HandleFunc("GET /api/audit", func(w http.ResponseWriter, r *http.Request) {
+		entries, err := getAuditLog(db, 0)
+		writeJSONResult(w, http.StatusOK, entries, err)
+	})
+
+	mux.HandleFunc("POST /api/audit/{auditid}/revert", func(w http.ResponseWriter, r *http.Request) {
+		auditid, err := strconv.Atoi(r.PathValue("auditid"))
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, r.PathValue("auditid")+" is not an audit ID")
+			return
+		}
+		revert := struct {
+			Reason string `json:"reason"`
+		}{}
+		json.NewDecoder(r.Body).Decode(&revert)
+		importMutex.Lock()
+		defer importMutex.Unlock()
+		e, err := revertAudit(db, auditid, revert.Reason)
+		writeJSONResult(w, http.StatusOK, e, err)
+	})
+
+	mux.HandleFunc("GET /api/standings", func(w http.ResponseWriter, r *http.Request) {
+		standings, err := getStandings(db, r.URL.Query().Get("series"))
+		writeJSONResult(w, http.StatusOK, standings, err)
+	})
+
+	mux.HandleFunc("GET /api/headtohead", func(w http.ResponseWriter, r *http.Request) {
+		records, err := getHeadToHead(db, r.URL.Query().Get("opponent"))
+		writeJSONResult(w, http.StatusOK, records, err)
+	})
+
+	mux.HandleFunc("GET /api/dreamteam", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("match") != "" {
+			id, err := strconv.Atoi(query.Get("match"))
+			if err != nil {
+				writeJSONError(w, http.StatusBadRequest, query.Get("match")+" is not a match ID")
+				return
+			}
+			team, err := getDreamTeam(db, []int{id})
+			writeJSONResult(w, http.StatusOK, team, err)
+			return
+		}
+		matchids, err := matchesBetween(db, query.Get("from"), query.Get("to"))
+		if errors.Is(err, errInvalid) {
+			writeJSONError(w, http.StatusBadRequest, "expected ?match=<id> or ?from=MM/DD/YYYY&to=MM/DD/YYYY")
+			return
+		} else if err != nil {
+			writeError(w, err)
+			return
+		}
+		team, err := getDreamTeam(db, matchids)
+		writeJSONResult(w, http.StatusOK, team, err)
+	})
+
+	mux.HandleFunc("POST /api/fantasy/entries", func(w http.ResponseWriter, r *http.Request) {
+		entry := fantasyEntry{}
+		if err := json.NewDecoder(r.Body).Decode(&entry); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "expected {\"member\": \"name\", \"matchid\": 12 or \"matchDate\": \"MM/DD/YYYY\", \"players\": [...], \"captain\": \"name\", \"viceCaptain\": \"name\"}")
+			return
+		}
+		importMutex.Lock()
+		defer importMutex.Unlock()
+		entry, err := saveFantasyEntry(db, entry)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		entries, err := getFantasyEntries(db, entry.MatchID, entry.MatchDate)
+		writeJSONResult(w, http.StatusCreated, entries, err)
+	})
+
+	mux.HandleFunc("GET /api/fantasy/matches/{id}", func(w http.ResponseWriter, r *http.Request) {
+		m, ok := matchParam(w, r, store)
+		if !ok {
+			return
+		}
+		entries, err := getFantasyEntries(db, m.MatchID, m.MatchDate)
+		writeJSONResult(w, http.StatusOK, entries, err)
+	})
+
+	mux.HandleFunc("GET /api/fantasy/prices", func(w http.ResponseWriter, r *http.Request) {
+		matchDate := r.URL.Query().Get("date")
+		if _, err := time.Parse("01/02/2006", matchDate); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "expected ?date=MM/DD/YYYY")
+			return
+		}
+		prices, err := getFantasyPrices(db, matchDate)
+		writeJSONResult(w, http.StatusOK, prices, err)
+	})
+
+	mux.HandleFunc("GET /api/fantasy/leaderboard", func(w http.ResponseWriter, r *http.Request) {
+		leaderboard, err := getFantasyLeaderboard(db, r.URL.Query().Get("series"))
+		writeJSONResult(w, http.StatusOK, leaderboard, err)
+	})
+
+	mux.HandleFunc("GET /api/records", func(w http.ResponseWriter, r *http.Request) {
+		records, err := getRecords(db)
+		writeJSONResult(w, http.StatusOK, records, err)
+	})
+
+	mux.HandleFunc("GET /api/matches/{id}/records", func(w http.ResponseWriter, r *http.Request) {
+		id, ok := matchIDParam(w, r, store)
+		if !ok {
+			return
+		}
+		records, err := getBrokenRecords(db, id)
+		writeJSONResult(w, http.StatusOK, records, err)
+	})
+
+	mux.HandleFunc("GET /api/awards", func(w http.ResponseWriter, r *http.Request) {
+		honors, err := getAwards(db, r.URL.Query().Get("series"))
+		writeJSONResult(w, http.StatusOK, honors, err)
+	})
+
+	mux.HandleFunc("GET /api/form", func(w http.ResponseWriter, r *http.Request) {
+		n := formDefaultMatches
+		if r.URL.Query().Get("matches") != "" {
+			var err error
+			n, err = strconv.Atoi(r.URL.Query().Get("matches"))
+			if err != nil || n < 1 {
+				writeJSONError(w, http.StatusBadRequest, "expected ?matches=<number of matches>")
+				return
+			}
+		}
+		form, err := getForm(db, n)
+		writeJSONResult(w, http.StatusOK, form, err)
+	})
+
+	mux.HandleFunc("GET /api/roles", func(w http.ResponseWriter, r *http.Request) {
+		roles, err := getPlayerRoles(db)
+		writeJSONResult(w, http.StatusOK, sortedPlayerRoles(roles), err)
+	})
+
+	mux.HandleFunc("POST /api/roles", func(w http.ResponseWriter, r *http.Request) {
+		role := roleRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&role); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "expected {\"player\": \"name\", \"role\": \"wicket-keeper\"}")
+			return
+		}
+		importMutex.Lock()
+		defer importMutex.Unlock()
+		if err := setPlayerRole(db, role.Player, role.Role, role.Reason); err != nil {
+			writeError(w, err)
+			return
+		}
+		roles, err := getPlayerRoles(db)
+		writeJSONResult(w, http.StatusOK, sortedPlayerRoles(roles), err)
+	})
+
+	mux.HandleFunc("GET /api/players/{name}", func(w http.ResponseWriter, r *http.Request) {
+		history, err := getPlayerMatchPoints(db, r.PathValue("name"))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if len(history) == 0 {
+			writeJSONError(w, http.StatusNotFound, "no matches found for player "+r.PathValue("name"))
+			return
+		}
+		stats, err := getPlayerProfile(db, r.PathValue("name"))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		profile := playerResponse{Player: r.PathValue("name"), Role: stats.Role, Matches: len(history), History: history, Career: stats.Career, Seasons: stats.Seasons}
+		for _, p := range history {
+			profile.Points = profile.Points + p.Points
+		}
+		writeJSON(w, http.StatusOK, profile)
+	})
+}
+
+// saveUploadedScorecard copies a scorecard csv posted either as the
+// "scorecard" field of a multipart form or as the raw request body to a
+// temporary file, the caller removes it.
+func saveUploadedScorecard(r *http.Request) (string, error) {
+
+	var upload io.Reader = r.Body
+	if file, _, err := r.FormFile("scorecard"); err == nil {
+		defer file.Close()
+		upload = file
+	}
+
+	f, err := os.CreateTemp("", "scorecard-*.csv")
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(f, upload)
+	f.Close()
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+
+	if err := validateScorecard(f.Name()); err != nil {
+		os.Remove(f.Name())
+		return "", invalidError(err.Error())
+	}
+	return f.Name(), nil
+}
+
+// importUploadedScorecard stores a posted scorecard and calculates the
+// points, returning the new match ID. The match is imported even when its
+// output files cannot be written, that is only logged.
+func importUploadedScorecard(store Store, r *http.Request) (int, error) {
+
+	scorecard, err := saveUploadedScorecard(r)
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(scorecard)
+
+	importMutex.Lock()
+	defer importMutex.Unlock()
+	parsed, err := parseScorecard(scorecard)
+	if err != nil {
+		return 0, err
+	}
+	id, err := store.FindMatch(parsed.Match)
+	if err != nil {
+		return 0, err
+	}
+	if id != 0 {
+		return id, conflictError("match already imported as Match ID " + strconv.Itoa(id))
+	}
+	ids, err := store.SaveScorecards([]parsedScorecard{parsed})
+	if err != nil {
+		return 0, err
+	}
+	if err = store.CalculatePoints(); err != nil {
+		return 0, err
+	}
+	if outputs, ok := store.(importOutputWriter); ok {
+		if err = outputs.writeImportOutputs(scorecard, ids[0]); err != nil {
+			log.Println("Match ID " + strconv.Itoa(ids[0]) + " imported, its output files were not written : " + err.Error())
+		}
+	}
+	return ids[0], nil
+}
+
+// previewUploadedScorecard parses a scorecard without saving anything.
+func previewUploadedScorecard(r *http.Request) (previewResponse, error) {
+
+	scorecard, err := saveUploadedScorecard(r)
+	if err != nil {
+		return previewResponse{}, err
+	}
+	defer os.Remove(scorecard)
+
+	importMutex.Lock()
+	defer importMutex.Unlock()
+	preview := previewResponse{}
+	if preview.Match, err = parseMatchDetails(scorecard); err != nil {
+		return preview, err
+	}
+	preview.Innings, err = extractInnings(scorecard, preview.Match.Team1, preview.Match.Team2)
+	return preview, err
+}
+
+func getPointsResponse(store Store, matchid int) ([]pointsResponse, error) {
+	roles, err := store.Roles()
+	if err != nil {
+		return nil, err
+	}
+	matchPoints, err := store.MatchPoints(matchid)
+	if err != nil {
+		return nil, err
+	}
+	points := make([]pointsResponse, 0)
+	for _, p := range matchPoints {
+		response := pointsResponse{Player: p.Player, Role: roles[strings.TrimSpace(p.Player)].Role, Components: make(map[string]int), Total: p.Total}
+		for j, c := range pointsComponents {
+			response.Components[c] = p.Components[j]
+		}
+		points = append(points, response)
+	}
+	return points, nil
+}
+
+func matchIDParam(w http.ResponseWriter, r *http.Request, store Store) (int, bool) {
+	m, ok := matchParam(w, r, store)
+	return m.MatchID, ok
+}
+
+// matchParam is the match whose ID is in the path, writing the error
+// response when there is no such match.
+func matchParam(w http.ResponseWriter, r *http.Request, store Store) (matchDetails, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, r.PathValue("id")+" is not a match ID")
+		return matchDetails{}, false
+	}
+	m, err := store.Match(id)
+	if errors.Is(err, errNotFound) {
+		writeJSONError(w, http.StatusNotFound, "match "+r.PathValue("id")+" not found")
+		return matchDetails{}, false
+	} else if err != nil {
+		writeError(w, err)
+		return matchDetails{}, false
+	}
+	return m, true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(v)
+	if err != nil {
+		log.Println(err.Error())
+	}
+}
+
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+// writeError answers a request that could not be done: 404, 400 or 409 for
+// what was asked for, 500 when the database or the server failed.
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errNotFound):
+		writeJSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, errInvalid):
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, errConflict):
+		writeJSONError(w, http.StatusConflict, err.Error())
+	default:
+		log.Println(err.Error())
+		writeJSONError(w, http.StatusInternalServerError, "internal error, see the server log")
+	}
+}
+
+// writeJSONResult writes v, or the error when there is one.
+func writeJSONResult(w http.ResponseWriter, status int, v interface{}, err error) {
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, status, v)
+}
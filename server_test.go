@@ -0,0 +1,40 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// The corrections endpoints answer 404 for a player or match that is not
+// there and 400 for a value that is not allowed, leaving the match alone.
+func TestCorrectionErrors(t *testing.T) {
+	db := openTestDB(t)
+	importTestScorecards(t, db, "scorecard.csv")
+	mux := http.NewServeMux()
+	registerStoreAPI(mux, &sqliteStore{db: db})
+	registerAPI(mux, db)
+
+	tests := []struct {
+		path, body string
+		status     int
+	}{
+		{"/api/matches/1/renames", `{"from": "Nobody", "to": "Somebody"}`, http.StatusNotFound},
+		{"/api/matches/9/renames", `{"from": "Jai V", "to": "Jai Venkat"}`, http.StatusNotFound},
+		{"/api/matches/1/adjustments", `{"player": "Nobody", "field": "OneRunOvers", "value": 1}`, http.StatusNotFound},
+		{"/api/matches/1/adjustments", `{"player": "Jai V", "field": "Sixes", "value": 1}`, http.StatusBadRequest},
+		{"/api/roles", `{"player": "Jai V", "role": "umpire"}`, http.StatusBadRequest},
+		{"/api/audit/99/revert", `{}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body)))
+		if w.Code != tt.status {
+			t.Errorf("POST %s %s : %d %s, want %d", tt.path, tt.body, w.Code, strings.TrimSpace(w.Body.String()), tt.status)
+		}
+	}
+	if there, err := checkPlayerThere(db, 1, "Jai V"); err != nil || !there {
+		t.Errorf("Jai V is no longer in match 1 : %v", err)
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// League points per result, and the overs a side is charged for when it is
+// bowled out, as used for net run rate.
+const (
+	standingsWinPoints  = 2
+	standingsTiePoints  = 1
+	standingsNRPoints   = 1
+	standingsFullInning = 20 * 6
+)
+
+type standingsRow struct {
+	Team        string  `json:"team"`
+	Played      int     `json:"played"`
+	Won         int     `json:"won"`
+	Lost        int     `json:"lost"`
+	Tied        int     `json:"tied"`
+	NoResult    int     `json:"noResult"`
+	Points      int     `json:"points"`
+	NetRunRate  float64 `json:"netRunRate"`
+	RunsFor     int     `json:"runsFor"`
+	BallsFaced  int     `json:"-"`
+	RunsAgainst int     `json:"runsAgainst"`
+	BallsBowled int     `json:"-"`
+	OversFaced  string  `json:"oversFaced"`
+	OversBowled string  `json:"oversBowled"`
+}
+
+type standingsTable struct {
+	Series   string         `json:"series"`
+	Division string         `json:"division"`
+	Teams    []standingsRow `json:"teams"`
+}
+
+// getStandings builds a standings table per season and division from the
+// imported match results and innings totals. series filters to one season
+// when it is not empty.
+func getStandings(db *sql.DB, series string) ([]standingsTable, error) {
+
+	tables := make([]standingsTable, 0)
+	rows := make(map[string]map[string]*standingsRow)
+
+	matches, err := getMatches(db)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range matches {
+		if series != "" && m.Series != series {
+			continue
+		}
+		key := m.Series + "|" + m.Division
+		if _, ok := rows[key]; !ok {
+			rows[key] = make(map[string]*standingsRow)
+			tables = append(tables, standingsTable{Series: m.Series, Division: m.Division})
+		}
+		teamRow := func(team string) *standingsRow {
+			if _, ok := rows[key][team]; !ok {
+				rows[key][team] = &standingsRow{Team: team}
+			}
+			return rows[key][team]
+		}
+		t1, t2 := teamRow(m.Team1), teamRow(m.Team2)
+		t1.Played = t1.Played + 1
+		t2.Played = t2.Played + 1
+
+		switch matchOutcome(m, m.Team1) {
+		case "won":
+			t1.Won, t2.Lost = t1.Won+1, t2.Lost+1
+		case "lost":
+			t2.Won, t1.Lost = t2.Won+1, t1.Lost+1
+		case "tied":
+			t1.Tied, t2.Tied = t1.Tied+1, t2.Tied+1
+		default:
+			t1.NoResult, t2.NoResult = t1.NoResult+1, t2.NoResult+1
+			// no result matches do not count towards net run rate
+			continue
+		}
+
+		totals, err := getInningsTotals(db, m.MatchID)
+		if err != nil {
+			return nil, err
+		}
+		for _, total := range totals {
+			balls := oversToBalls(total.Overs)
+			if total.Wickets >= 10 {
+				balls = standingsFullInning
+			}
+			batting, bowling := t1, t2
+			if total.Team == m.Team2 {
+				batting, bowling = t2, t1
+			}
+			batting.RunsFor = batting.RunsFor + total.Runs
+			batting.BallsFaced = batting.BallsFaced + balls
+			bowling.RunsAgainst = bowling.RunsAgainst + total.Runs
+			bowling.BallsBowled = bowling.BallsBowled + balls
+		}
+	}
+
+	for i := range tables {
+		for _, r := range rows[tables[i].Series+"|"+tables[i].Division] {
+			r.Points = r.Won*standingsWinPoints + r.Tied*standingsTiePoints + r.NoResult*standingsNRPoints
+			if r.BallsFaced > 0 && r.BallsBowled > 0 {
+				r.NetRunRate = float64(r.RunsFor)*6/float64(r.BallsFaced) - float64(r.RunsAgainst)*6/float64(r.BallsBowled)
+			}
+			r.OversFaced = ballsToOvers(r.BallsFaced)
+			r.OversBowled = ballsToOvers(r.BallsBowled)
+			tables[i].Teams = append(tables[i].Teams, *r)
+		}
+		sort.Slice(tables[i].Teams, func(a, b int) bool {
+			ta, tb := tables[i].Teams[a], tables[i].Teams[b]
+			if ta.Points != tb.Points {
+				return ta.Points > tb.Points
+			}
+			if ta.NetRunRate != tb.NetRunRate {
+				return ta.NetRunRate > tb.NetRunRate
+			}
+			return ta.Team < tb.Team
+		})
+	}
+	return tables, nil
+}
+
+// matchOutcome reads the match result from team's point of view:
+// "won", "lost", "tied" or "noresult".
+func matchOutcome(m matchDetails, team string) string {
+	result := strings.ToLower(m.Result)
+	opponentTeam := m.Team1
+	if m.Team1 == team {
+		opponentTeam = m.Team2
+	}
+	switch {
+	case strings.HasPrefix(result, strings.ToLower(team)+" won"):
+		return "won"
+	case strings.HasPrefix(result, strings.ToLower(opponentTeam)+" won"):
+		return "lost"
+	case strings.Contains(result, "tie"):
+		return "tied"
+	}
+	return "noresult"
+}
+
+func getInningsTotals(db *sql.DB, matchid int) ([]inningsTotal, error) {
+
+	row, err := db.Query("select team, runs, wickets, overs from innings where matchid = ?", matchid)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	totals := make([]inningsTotal, 0)
+	for row.Next() {
+		total := inningsTotal{}
+		if err := row.Scan(&total.Team, &total.Runs, &total.Wickets, &total.Overs); err != nil {
+			return nil, err
+		}
+		totals = append(totals, total)
+	}
+	return totals, row.Err()
+}
+
+func renderStandings(tables []standingsTable) {
+	for _, s := range tables {
+		t := table.NewWriter()
+		t.AppendHeader(table.Row{"Pos", "Team", "P", "W", "L", "T", "NR", "Pts", "NRR", "For", "Against"})
+		for i, r := range s.Teams {
+			t.AppendRow(table.Row{i + 1, r.Team, r.Played, r.Won, r.Lost, r.Tied, r.NoResult, r.Points, fmt.Sprintf("%+.3f", r.NetRunRate),
+				fmt.Sprintf("%d/%s", r.RunsFor, r.OversFaced), fmt.Sprintf("%d/%s", r.RunsAgainst, r.OversBowled)})
+		}
+		fmt.Println("------------------------------------------")
+		fmt.Println("Standings : " + s.Series + " " + s.Division)
+		fmt.Println("------------------------------------------")
+		fmt.Println(t.Render())
+	}
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/lib/pq" // Import the PostgreSQL driver
+)
+
+// postgresMigrationFiles are the PostgreSQL schema changes, numbered like
+// the SQLite ones but kept apart as the SQL differs.
+//
+//go:embed migrations/postgres/*.sql
+var postgresMigrationFiles embed.FS
+
+// postgresLock is the advisory lock key that keeps two scorers from
+// migrating or recalculating the points of a shared database at once.
+const postgresLock = 20220302
+
+// postgresStore is the Store over a PostgreSQL database shared by the
+// league. The queries follow the SQLite ones, with $n placeholders and
+// single quoted strings.
+type postgresStore struct {
+	db *sql.DB
+}
+
+// openPostgresStore connects to the database and brings it up to the
+// current schema.
+func openPostgresStore(databaseURL string) *postgresStore {
+	log.Println("Creating PostgreSQL connection to " + databaseURLEnv + " ...")
+	db, err := sql.Open("postgres", databaseURL)
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+	if err = db.Ping(); err != nil {
+		log.Fatalln("Could not connect to PostgreSQL : " + err.Error())
+	}
+	log.Println("Connection created to PostgreSQL ..")
+
+	s := &postgresStore{db: db}
+	s.migrate()
+	var hasPoints bool
+	if err = db.QueryRow(`select to_regclass('totalmatchpoints') IS NOT NULL`).Scan(&hasPoints); err != nil {
+		log.Fatal(err)
+	}
+	if !hasPoints {
+		if err = s.CalculatePoints(); err != nil {
+			log.Fatalln(err.Error())
+		}
+	}
+	return s
+}
+
+// migrate applies the PostgreSQL migrations not applied yet, each in its
+// own transaction together with its schema_version row.
+func (s *postgresStore) migrate() {
+	err := execQuery(s.db, `CREATE TABLE IF NOT EXISTS schema_version (
+		version INTEGER NOT NULL PRIMARY KEY,
+		name TEXT,
+		appliedAt TEXT
+	  )`, "Creating Schema Version Table")
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+
+	for _, m := range loadMigrations(postgresMigrationFiles, "migrations/postgres") {
+		tx, err := s.db.Begin()
+		if err != nil {
+			log.Fatalln(err.Error())
+		}
+		var applied int
+		if _, err = tx.Exec(`select pg_advisory_xact_lock($1)`, postgresLock); err == nil {
+			err = tx.QueryRow(`select count(*) from schema_version where version = $1`, m.Version).Scan(&applied)
+		}
+		if err != nil {
+			tx.Rollback()
+			log.Fatalln(err.Error())
+		}
+		if applied > 0 {
+			tx.Rollback()
+			continue
+		}
+		if _, err = tx.Exec(m.SQL); err != nil {
+			tx.Rollback()
+			log.Fatalln("Migration " + m.Name + " failed : " + err.Error())
+		}
+		_, err = tx.Exec(`INSERT INTO schema_version (version,name,appliedAt) VALUES ($1,$2,$3)`, m.Version, m.Name, time.Now().Format("2006-01-02 15:04:05"))
+		if err != nil {
+			tx.Rollback()
+			log.Fatalln(err.Error())
+		}
+		if err = tx.Commit(); err != nil {
+			log.Fatalln(err.Error())
+		}
+		log.Println("Applied migration " + m.Name)
+	}
+}
+
+func (s *postgresStore) Matches() ([]matchDetails, error) {
+
+	row, err := s.db.Query("select matchid, series, stage, division, matchDate, Team1, Team2, Result from match order by matchid")
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	matches := make([]matchDetails, 0)
+	for row.Next() {
+		m := matchDetails{}
+		if err := row.Scan(&m.MatchID, &m.Series, &m.Stage, &m.Division, &m.MatchDate, &m.Team1, &m.Team2, &m.Result); err != nil {
+			return nil, err
+		}
+		matches = append(matches, m)
+	}
+	return matches, row.Err()
+}
+
+func (s *postgresStore) Match(matchid int) (matchDetails, error) {
+
+	m := matchDetails{}
+	matchSQL := "select matchid, series, stage, division, matchDate, Team1, Team2, Result from match where matchid = $1"
+	err := s.db.QueryRow(matchSQL, matchid).Scan(&m.MatchID, &m.Series, &m.Stage, &m.Division, &m.MatchDate, &m.Team1, &m.Team2, &m.Result)
+	if err == sql.ErrNoRows {
+		return m, notFoundError("there is no Match ID " + strconv.Itoa(matchid))
+	}
+	return m, err
+}
+
+func (s *postgresStore) FindMatch(m matchDetails) (int, error) {
+	var id int
+	err := s.db.QueryRow(`select matchid from match where matchDate = $1 AND Team1 = $2 AND Team2 = $3`, m.MatchDate, m.Team1, m.Team2).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return id, err
+}
+
+func (s *postgresStore) SaveScorecards(parsed []parsedScorecard) ([]int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	// prepare and exec keep the first error, checked after each match
+	prepare := func(query string) *sql.Stmt {
+		if err != nil {
+			return nil
+		}
+		var statement *sql.Stmt
+		statement, err = tx.Prepare(query)
+		return statement
+	}
+	exec := func(statement *sql.Stmt, args ...interface{}) {
+		if err == nil {
+			_, err = statement.Exec(args...)
+		}
+	}
+	match := prepare(`INSERT INTO match (series,stage,division,matchDate,Team1,Team2,Result) VALUES ($1,$2,$3,$4,$5,$6,$7) RETURNING matchid`)
+	batsmen := prepare(`INSERT INTO batsmen (matchid,battername,runs,balls,fours,sixers,Notout) VALUES ($1,$2,$3,$4,$5,$6,$7)`)
+	bowlers := prepare(`INSERT INTO bowlers (matchid,bowlerName,overs,Maidens,RunsGiven,wickets,Wides,NoBalls) VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`)
+	fielders := prepare(`INSERT INTO fielders (matchid,Batsman,wicketType,fieldername,bowlername,bowled,catches,runouts) VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`)
+	inning := prepare(`INSERT INTO innings (matchid,team,runs,wickets,overs) VALUES ($1,$2,$3,$4,$5)`)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(parsed))
+	for _, p := range parsed {
+		m := p.Match
+		var id int
+		if err = match.QueryRow(m.Series, m.Stage, m.Division, m.MatchDate, m.Team1, m.Team2, m.Result).Scan(&id); err != nil {
+			return nil, err
+		}
+		for _, b := range p.Batting {
+			exec(batsmen, id, b.Name, postgresInt(b.Runs), postgresInt(b.Balls), postgresInt(b.Fours), postgresInt(b.Sixers), b.NotOut)
+		}
+		for _, b := range p.Bowling {
+			exec(bowlers, id, b.Name, b.Overs, postgresInt(b.Maidens), postgresInt(b.RunsGiven), postgresInt(b.Wickets), postgresInt(b.Wides), postgresInt(b.NoBalls))
+		}
+		for _, f := range p.Fielding {
+			exec(fielders, id, f.Batsman, f.WicketType, f.Fielder, f.Bowler, f.Bowled, f.Catches, f.RunOuts)
+		}
+		for _, total := range p.Innings {
+			exec(inning, id, total.Team, total.Runs, total.Wickets, total.Overs)
+		}
+		if err != nil {
+			return nil, err
+		}
+		log.Println("Match Saved as Match ID := " + fmt.Sprint(id))
+		ids = append(ids, id)
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// postgresInt is a scorecard figure as the number SQLite's INTEGER affinity
+// makes of it in the points, 0 when it is not a number.
+func postgresInt(figure string) int {
+	n, _ := strconv.Atoi(strings.TrimSpace(figure))
+	return n
+}
+
+func (s *postgresStore) Players() ([]string, error) {
+
+	row, err := s.db.Query(`select player from (select distinct TRIM(battername) as player from batsmen) p order by player COLLATE "C"`)
+	if err != nil {
+		return nil, err
+	}
+	return scanPlayers(row)
+}
+
+func (s *postgresStore) Roles() (map[string]playerRole, error) {
+	return getPlayerRoles(s.db)
+}
+
+func (s *postgresStore) Scorecard(matchid int) (parsedScorecard, error) {
+	m, err := s.Match(matchid)
+	if err != nil {
+		return parsedScorecard{}, err
+	}
+	return readScorecard(s.db, scorecardSQL{
+		batting:  `select battername, runs, balls, fours, sixers, Notout from batsmen where matchid = $1 order by batterid`,
+		bowling:  `select bowlerName, overs, Maidens, RunsGiven, wickets, Wides, NoBalls from bowlers where matchid = $1 order by bowlerid`,
+		fielding: `select Batsman, wicketType, fieldername, bowlername, bowled, catches, runouts from fielders where matchid = $1 order by fieldingid`,
+		innings:  `select team, runs, wickets, overs from innings where matchid = $1 order by inningsid`,
+	}, m)
+}
+
+// postgresDialect is the points SQL for PostgreSQL, its LIKE is case
+// sensitive where SQLite's is not.
+var postgresDialect = sqlDialect{Real: "numeric", ILike: "ILIKE", Param: "$"}
+
+// CalculatePoints rebuilds TotalMatchPoints the way calculatePoints does,
+// in one transaction so the other scorers keep reading the old points
+// until the new ones are ready.
+func (s *postgresStore) CalculatePoints() error {
+
+	createPointsTableSQL, err := pointsTableSQL(activeRules, postgresDialect)
+	if err != nil {
+		return err
+	}
+	roles, err := getPlayerRoles(s.db)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	exec := func(query string, args ...interface{}) {
+		if err == nil {
+			_, err = tx.Exec(query, args...)
+		}
+	}
+	exec(`select pg_advisory_xact_lock($1)`, postgresLock)
+	exec(`DROP TABLE IF EXISTS TotalMatchPoints`)
+	exec(createPointsTableSQL)
+	exec(`CREATE INDEX totalmatchpoints_player ON TotalMatchPoints (Player, matchid)`)
+	for field, perUnit := range adjustmentPoints {
+		exec(adjustmentSQL(postgresDialect, field), perUnit, field)
+	}
+	for _, bonus := range roleBonuses(activeRules) {
+		for _, r := range roles {
+			if r.Role == bonus.Role {
+				exec(roleBonusSQL(postgresDialect), bonus.Points, bonus.WicketType, r.Player)
+			}
+		}
+	}
+	exec(totalPointsSQL)
+	if err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	log.Println("Points Table Rebuilt")
+	return nil
+}
+
+func (s *postgresStore) MatchPoints(matchid int) ([]playerPoints, error) {
+
+	pointsSQL := `select Player, ` + strings.Join(pointsComponents, ", ") + `, "Total Points" from TotalMatchPoints where matchid = $1 order by "Total Points" DESC, Player COLLATE "C"`
+	row, err := s.db.Query(pointsSQL, matchid)
+	if err != nil {
+		return nil, err
+	}
+	return scanPlayerPoints(row)
+}
+
+func (s *postgresStore) PlayerPoints(playerName string) ([]playerMatchPoints, error) {
+
+	pointsSQL := `select matchid, MatchDate, Opponent, "Total Points" from TotalMatchPoints where TRIM(Player) = TRIM($1) order by matchid`
+	row, err := s.db.Query(pointsSQL, playerName)
+	if err != nil {
+		return nil, err
+	}
+	return scanPlayerMatchPoints(row)
+}
+
+func (s *postgresStore) Leaderboard(series string) ([]leaderboardEntry, error) {
+
+	leaderboardSQL := `select p.Player, count(*), sum(p."Total Points")
+		from TotalMatchPoints p JOIN match m ON p.matchid = m.matchid
+		where ($1 = '' OR m.series = $1)
+		group by p.Player order by sum(p."Total Points") DESC, p.Player COLLATE "C"`
+
+	row, err := s.db.Query(leaderboardSQL, series)
+	if err != nil {
+		return nil, err
+	}
+	return scanLeaderboard(row)
+}
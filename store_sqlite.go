@@ -0,0 +1,87 @@
+package main
+
+import (
+	"database/sql"
+)
+
+// sqliteStore is the Store over phoenixPoints.db, the functions every
+// other command uses.
+type sqliteStore struct {
+	db *sql.DB
+}
+
+func (s *sqliteStore) Matches() ([]matchDetails, error) {
+	return getMatches(s.db)
+}
+
+func (s *sqliteStore) Match(matchid int) (matchDetails, error) {
+	return getMatchDetails(s.db, matchid)
+}
+
+func (s *sqliteStore) FindMatch(m matchDetails) (int, error) {
+	return findImportedMatch(s.db, m)
+}
+
+func (s *sqliteStore) SaveScorecards(parsed []parsedScorecard) ([]int, error) {
+	writer, err := newScorecardWriter(s.db)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int, 0, len(parsed))
+	for _, p := range parsed {
+		id, err := writer.write(p)
+		if err != nil {
+			writer.tx.Rollback()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, writer.commit()
+}
+
+func (s *sqliteStore) Players() ([]string, error) {
+	return getPlayers(s.db)
+}
+
+func (s *sqliteStore) Roles() (map[string]playerRole, error) {
+	return getPlayerRoles(s.db)
+}
+
+func (s *sqliteStore) Scorecard(matchid int) (parsedScorecard, error) {
+	m, err := getMatchDetails(s.db, matchid)
+	if err != nil {
+		return parsedScorecard{}, err
+	}
+	return readScorecard(s.db, scorecardSQL{
+		batting:  `select battername, runs, balls, fours, sixers, Notout from batsmen where matchid = ? order by batterid`,
+		bowling:  `select bowlerName, overs, Maidens, RunsGiven, wickets, Wides, NoBalls from bowlers where matchid = ? order by bowlerid`,
+		fielding: `select Batsman, wicketType, fieldername, bowlername, bowled, catches, runouts from fielders where matchid = ? order by fieldingid`,
+		innings:  `select team, runs, wickets, overs from innings where matchid = ? order by rowid`,
+	}, m)
+}
+
+func (s *sqliteStore) CalculatePoints() error {
+	return calculatePoints(s.db)
+}
+
+func (s *sqliteStore) MatchPoints(matchid int) ([]playerPoints, error) {
+	return getMatchPoints(s.db, matchid)
+}
+
+func (s *sqliteStore) PlayerPoints(playerName string) ([]playerMatchPoints, error) {
+	return getPlayerMatchPoints(s.db, playerName)
+}
+
+func (s *sqliteStore) Leaderboard(series string) ([]leaderboardEntry, error) {
+	return getLeaderboard(s.db, series)
+}
+
+// writeImportOutputs writes the match's output files and announces the
+// records it broke, the way an interactive import does.
+func (s *sqliteStore) writeImportOutputs(scorecard string, id int) error {
+	matchid, currentMatch = id, id
+	if err := writeMatchOutputs(scorecard, s.db); err != nil {
+		return err
+	}
+	return announceRecords(s.db, id)
+}
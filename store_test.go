@@ -0,0 +1,209 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// storeTestDatabaseURLEnv names a PostgreSQL database to run the Store
+// tests against too. They run in a schema of their own, dropped after.
+const storeTestDatabaseURLEnv = "PHOENIX_TEST_DATABASE_URL"
+
+// testStores are an empty sqliteStore and, when PHOENIX_TEST_DATABASE_URL is
+// set, an empty postgresStore. CI has to set it, so postgresStore is tested
+// there.
+func testStores(t *testing.T) map[string]Store {
+	t.Helper()
+	stores := map[string]Store{"sqlite": &sqliteStore{db: openTestDB(t)}}
+	databaseURL := os.Getenv(storeTestDatabaseURLEnv)
+	if databaseURL == "" {
+		if os.Getenv("CI") != "" {
+			t.Fatal(storeTestDatabaseURLEnv + " is not set, CI has to test postgresStore")
+		}
+		return stores
+	}
+	admin, err := sql.Open("postgres", databaseURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := fmt.Sprintf("phoenix_test_%d", time.Now().UnixNano())
+	if _, err := admin.Exec(`CREATE SCHEMA ` + schema); err != nil {
+		admin.Close()
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec(`DROP SCHEMA ` + schema + ` CASCADE`); err != nil {
+			t.Error(err)
+		}
+		admin.Close()
+	})
+	u, err := url.Parse(databaseURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := u.Query()
+	query.Set("search_path", schema)
+	u.RawQuery = query.Encode()
+	store := openPostgresStore(u.String())
+	t.Cleanup(func() { store.db.Close() })
+	stores["postgres"] = store
+	return stores
+}
+
+// storeResults are what a store gives back for the test scorecards, for
+// comparing the stores with each other.
+type storeResults struct {
+	Players     []string
+	Scorecards  []parsedScorecard
+	MatchPoints [][]playerPoints
+	Leaderboard []leaderboardEntry
+}
+
+// Every Store keeps and reads back matches and calculates points the same
+// way, the postgresStore giving what the sqliteStore gives.
+func TestStoreConformance(t *testing.T) {
+	parsed := make([]parsedScorecard, 0)
+	for _, scorecard := range []string{"scorecard.csv", "Mar3-20.csv"} {
+		p, err := parseScorecard(scorecard)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed = append(parsed, p)
+	}
+
+	results := make(map[string]storeResults)
+	stores := testStores(t)
+	for _, name := range []string{"sqlite", "postgres"} {
+		store, ok := stores[name]
+		t.Run(name, func(t *testing.T) {
+			if !ok {
+				t.Skip(storeTestDatabaseURLEnv + " is not set")
+			}
+			results[name] = checkStore(t, store, parsed)
+		})
+	}
+	if pg, ok := results["postgres"]; ok && !reflect.DeepEqual(pg, results["sqlite"]) {
+		t.Errorf("postgresStore gives\n%+v\nsqliteStore gives\n%+v", pg, results["sqlite"])
+	}
+}
+
+func checkStore(t *testing.T, store Store, parsed []parsedScorecard) storeResults {
+	results := storeResults{}
+
+	if matches, err := store.Matches(); err != nil || len(matches) != 0 {
+		t.Fatalf("empty store has matches %v : %v", matches, err)
+	}
+	if _, err := store.Match(1); !errors.Is(err, errNotFound) {
+		t.Errorf("Match of an unknown match : %v, want not found", err)
+	}
+	if id, err := store.FindMatch(parsed[0].Match); err != nil || id != 0 {
+		t.Errorf("FindMatch before saving : %d %v, want 0", id, err)
+	}
+
+	ids, err := store.SaveScorecards(parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != len(parsed) || ids[0] == ids[1] {
+		t.Fatalf("SaveScorecards gave match IDs %v", ids)
+	}
+	matches, err := store.Matches()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != len(parsed) {
+		t.Fatalf("%d matches saved, want %d", len(matches), len(parsed))
+	}
+	for i, id := range ids {
+		want := parsed[i].Match
+		want.MatchID = id
+		if matches[i] != want {
+			t.Errorf("Matches()[%d] %+v, want %+v", i, matches[i], want)
+		}
+		if m, err := store.Match(id); err != nil || m != want {
+			t.Errorf("Match(%d) %+v %v, want %+v", id, m, err, want)
+		}
+		if found, err := store.FindMatch(parsed[i].Match); err != nil || found != id {
+			t.Errorf("FindMatch of match %d : %d %v", id, found, err)
+		}
+
+		scorecard, err := store.Scorecard(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(scorecard.Batting) != len(parsed[i].Batting) || len(scorecard.Bowling) != len(parsed[i].Bowling) ||
+			len(scorecard.Fielding) != len(parsed[i].Fielding) || len(scorecard.Innings) != len(parsed[i].Innings) {
+			t.Errorf("Scorecard(%d) has %d/%d/%d/%d rows, want %d/%d/%d/%d", id,
+				len(scorecard.Batting), len(scorecard.Bowling), len(scorecard.Fielding), len(scorecard.Innings),
+				len(parsed[i].Batting), len(parsed[i].Bowling), len(parsed[i].Fielding), len(parsed[i].Innings))
+		}
+		for j, b := range scorecard.Batting {
+			if j < len(parsed[i].Batting) && b.Name != parsed[i].Batting[j].Name {
+				t.Errorf("Scorecard(%d) batter %d is %q, want %q", id, j, b.Name, parsed[i].Batting[j].Name)
+			}
+		}
+		scorecard.Match.MatchID = i + 1
+		results.Scorecards = append(results.Scorecards, scorecard)
+	}
+
+	results.Players, err = store.Players()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results.Players) == 0 || !sort.StringsAreSorted(results.Players) {
+		t.Errorf("Players() %v, want the batters in name order", results.Players)
+	}
+	if _, err := store.Roles(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.CalculatePoints(); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range ids {
+		points, err := store.MatchPoints(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(points) == 0 {
+			t.Errorf("MatchPoints(%d) has no players", id)
+		}
+		for _, p := range points {
+			sum := 0
+			for _, c := range p.Components {
+				sum = sum + c
+			}
+			if sum != p.Total {
+				t.Errorf("MatchPoints(%d) %s totals %d, the components add up to %d", id, p.Player, p.Total, sum)
+			}
+		}
+		sort.Slice(points, func(a, b int) bool { return points[a].Player < points[b].Player })
+		results.MatchPoints = append(results.MatchPoints, points)
+	}
+
+	results.Leaderboard, err = store.Leaderboard("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range results.Leaderboard {
+		matchPoints, err := store.PlayerPoints(e.Player)
+		if err != nil {
+			t.Fatal(err)
+		}
+		total := 0
+		for _, p := range matchPoints {
+			total = total + p.Points
+		}
+		if len(matchPoints) != e.Matches || total != e.Points {
+			t.Errorf("%s has %d points in %d matches, the leaderboard says %d in %d", e.Player, total, len(matchPoints), e.Points, e.Matches)
+		}
+	}
+	return results
+}
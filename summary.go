@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+type batterFigures struct {
+	Player string
+	Runs   int
+	Balls  int
+	Fours  int
+	Sixers int
+	NotOut bool
+}
+
+type bowlerFigures struct {
+	Player    string
+	Overs     string
+	RunsGiven int
+	Wickets   int
+	Maidens   int
+}
+
+type fielderFigures struct {
+	Player  string
+	Catches int
+	Runouts int
+}
+
+type matchSummary struct {
+	Match       matchDetails
+	Top         []playerPoints
+	BestBatter  *batterFigures
+	BestBowler  *bowlerFigures
+	BestFielder *fielderFigures
+	Ducks       []string
+}
+
+// summaryTemplates are the built-in chat summaries. Dropping a
+// summary.md.tmpl or summary.txt.tmpl file in the working directory
+// replaces the matching one.
+var summaryTemplates = map[string]string{
+	"markdown": `**{{.Match.Team1}} vs {{.Match.Team2}}** ({{.Match.MatchDate}}, {{.Match.Division}})
+{{.Match.Result}}
+
+**Fantasy Top 3**
+{{range $i, $p := .Top}}{{inc $i}}. {{$p.Player}} - {{$p.Total}} pts
+{{end}}
+{{with .BestBatter}}**Best Batter:** {{.Player}} {{.Runs}}{{if .NotOut}}*{{end}} ({{.Balls}}b, {{.Fours}}x4, {{.Sixers}}x6)
+{{end}}{{with .BestBowler}}**Best Bowler:** {{.Player}} {{.Wickets}}/{{.RunsGiven}} ({{.Overs}} ov)
+{{end}}{{with .BestFielder}}**Best Fielder:** {{.Player}} ({{.Catches}} ct, {{.Runouts}} ro)
+{{end}}{{if .Ducks}}**Ducks:** {{join .Ducks ", "}}
+{{end}}`,
+	"text": `{{.Match.Team1}} vs {{.Match.Team2}} ({{.Match.MatchDate}}, {{.Match.Division}})
+{{.Match.Result}}
+
+Fantasy Top 3
+{{range $i, $p := .Top}}{{inc $i}}. {{$p.Player}} - {{$p.Total}} pts
+{{end}}
+{{with .BestBatter}}Best Batter : {{.Player}} {{.Runs}}{{if .NotOut}}*{{end}} ({{.Balls}}b, {{.Fours}}x4, {{.Sixers}}x6)
+{{end}}{{with .BestBowler}}Best Bowler : {{.Player}} {{.Wickets}}/{{.RunsGiven}} ({{.Overs}} ov)
+{{end}}{{with .BestFielder}}Best Fielder : {{.Player}} ({{.Catches}} ct, {{.Runouts}} ro)
+{{end}}{{if .Ducks}}Ducks : {{join .Ducks ", "}}
+{{end}}`,
+}
+
+var summaryTemplateFiles = map[string]string{
+	"markdown": "summary.md.tmpl",
+	"text":     "summary.txt.tmpl",
+}
+
+func getMatchSummary(db *sql.DB, matchid int) (matchSummary, error) {
+
+	var err error
+	summary := matchSummary{}
+	if summary.Match, err = getMatchDetails(db, matchid); err != nil {
+		return summary, err
+	}
+	if summary.Top, err = getMatchPoints(db, matchid); err != nil {
+		return summary, err
+	}
+	if len(summary.Top) > 3 {
+		summary.Top = summary.Top[:3]
+	}
+
+	batter := batterFigures{}
+	var notout int
+	batterSQL := "select battername, runs, balls, fours, sixers, Notout from batsmen where matchid = ? order by runs DESC, balls ASC LIMIT 1"
+	err = db.QueryRow(batterSQL, matchid).Scan(&batter.Player, &batter.Runs, &batter.Balls, &batter.Fours, &batter.Sixers, &notout)
+	if err != nil && err != sql.ErrNoRows {
+		return summary, err
+	}
+	if err == nil && batter.Runs > 0 {
+		batter.NotOut = notout == 1
+		summary.BestBatter = &batter
+	}
+
+	bowler := bowlerFigures{}
+	bowlerSQL := "select bowlerName, overs, RunsGiven, wickets, Maidens from bowlers where matchid = ? order by wickets DESC, RunsGiven ASC LIMIT 1"
+	err = db.QueryRow(bowlerSQL, matchid).Scan(&bowler.Player, &bowler.Overs, &bowler.RunsGiven, &bowler.Wickets, &bowler.Maidens)
+	if err != nil && err != sql.ErrNoRows {
+		return summary, err
+	}
+	if err == nil {
+		summary.BestBowler = &bowler
+	}
+
+	fielder := fielderFigures{}
+	fielderSQL := `select fieldername, sum(catches), sum(runouts) from fielders where matchid = ? AND TRIM(fieldername) <> ''
+		group by fieldername order by sum(catches) + sum(runouts) DESC, sum(catches) DESC LIMIT 1`
+	err = db.QueryRow(fielderSQL, matchid).Scan(&fielder.Player, &fielder.Catches, &fielder.Runouts)
+	if err != nil && err != sql.ErrNoRows {
+		return summary, err
+	}
+	if err == nil {
+		summary.BestFielder = &fielder
+	}
+
+	row, err := db.Query("select b.battername from batsmen b where b.matchid = ? AND "+duckSQL, matchid)
+	if err != nil {
+		return summary, err
+	}
+	defer row.Close()
+	for row.Next() {
+		var duck string
+		if err := row.Scan(&duck); err != nil {
+			return summary, err
+		}
+		summary.Ducks = append(summary.Ducks, duck)
+	}
+	return summary, row.Err()
+}
+
+// renderMatchSummary renders the match summary in "markdown" or "text" format.
+func renderMatchSummary(db *sql.DB, matchid int, format string) (string, error) {
+
+	summaryTemplate, ok := summaryTemplates[format]
+	if !ok {
+		return "", invalidError("Unknown summary format " + format + ", use markdown or text")
+	}
+	if fileExists(summaryTemplateFiles[format]) {
+		customTemplate, err := os.ReadFile(summaryTemplateFiles[format])
+		if err != nil {
+			return "", err
+		}
+		summaryTemplate = string(customTemplate)
+	}
+
+	t, err := template.New(format).Funcs(template.FuncMap{
+		"inc":  func(i int) int { return i + 1 },
+		"join": strings.Join,
+	}).Parse(summaryTemplate)
+	if err != nil {
+		return "", errors.New("Summary template " + summaryTemplateFiles[format] + " : " + err.Error())
+	}
+
+	summary, err := getMatchSummary(db, matchid)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	if err = t.Execute(&out, summary); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// writeMatchSummary writes the Markdown summary to summary_<matchid>.md,
+// next to the points_<matchid>.csv file.
+func writeMatchSummary(db *sql.DB) error {
+	summaryFile := "summary_" + strconv.Itoa(matchid) + ".md"
+	summary, err := renderMatchSummary(db, matchid, "markdown")
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(summaryFile, []byte(summary), 0644); err != nil {
+		return err
+	}
+	log.Println("Match summary : " + summaryFile + " created ")
+	return nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Watch mode: how often the folder is checked, how long a file has to be
+// left alone before it is read (the scorer may still be copying it) and
+// where failed files go, inside the watched folder.
+const (
+	watchDefaultPoll = 30 * time.Second
+	watchSettleTime  = 5 * time.Second
+	quarantineDir    = "quarantine"
+	leaderboardFile  = "leaderboard.csv"
+)
+
+// watchFolder imports new scorecards dropped into dir every poll until the
+// process is stopped.
+func watchFolder(db *sql.DB, dir string, poll time.Duration) {
+	log.Println("Watching " + dir + " for new scorecards every " + poll.String() + " ...")
+	for {
+		watchOnce(db, dir)
+		time.Sleep(poll)
+	}
+}
+
+// watchOnce imports the scorecards in dir that have not been processed in
+// their current version, quarantines the ones that fail and regenerates
+// the leaderboard when anything was imported.
+func watchOnce(db *sql.DB, dir string) {
+
+	matches, err := filepath.Glob(filepath.Join(dir, "*.csv"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	files := make([]string, 0)
+	infos := make(map[string]os.FileInfo)
+	for _, f := range matches {
+		info, err := os.Stat(f)
+		if err != nil || isGeneratedFile(f) {
+			continue
+		}
+		if time.Since(info.ModTime()) < watchSettleTime || isProcessedFile(db, f, info) {
+			continue
+		}
+		files = append(files, f)
+		infos[f] = info
+	}
+	if len(files) == 0 {
+		return
+	}
+
+	batch, err := batchImport(&sqliteStore{db: db}, files)
+	if err != nil {
+		log.Fatal(err)
+	}
+	renderBatchImport(batch)
+	imported := 0
+	for _, b := range batch {
+		recordProcessedFile(db, b, infos[b.Path])
+		switch b.Status {
+		case batchImported:
+			imported = imported + 1
+		case batchFailed:
+			quarantineFile(dir, b)
+		}
+	}
+	if imported > 0 {
+		writeLeaderboardOutputs(db)
+	}
+}
+
+func isProcessedFile(db *sql.DB, path string, info os.FileInfo) bool {
+	var count int
+	processedSQL := `select count(*) from processed_files where path = ? AND size = ? AND modTime = ?`
+	err := db.QueryRow(processedSQL, path, info.Size(), info.ModTime().Format(time.RFC3339Nano)).Scan(&count)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return count > 0
+}
+
+func recordProcessedFile(db *sql.DB, b batchFile, info os.FileInfo) {
+	insertProcessedSQL := `INSERT INTO processed_files (path,size,modTime,status,matchid,processedAt) VALUES (?,?,?,?,?,?)`
+	_, err := db.Exec(insertProcessedSQL, b.Path, info.Size(), info.ModTime().Format(time.RFC3339Nano), b.Status, b.MatchID, time.Now().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+}
+
+// quarantineFile moves a failed scorecard to the quarantine folder with a
+// <file>.error.txt next to it saying why it failed.
+func quarantineFile(dir string, b batchFile) {
+	quarantine := filepath.Join(dir, quarantineDir)
+	if err := os.MkdirAll(quarantine, 0755); err != nil {
+		log.Fatal(err)
+	}
+	target := filepath.Join(quarantine, filepath.Base(b.Path))
+	if err := os.Rename(b.Path, target); err != nil {
+		log.Println("Could not quarantine " + b.Path + " : " + err.Error())
+		return
+	}
+	report := fmt.Sprintf("%s failed to import on %s\n%s\n", filepath.Base(b.Path), time.Now().Format("2006-01-02 15:04:05"), b.Reason)
+	if err := os.WriteFile(target+".error.txt", []byte(report), 0644); err != nil {
+		log.Fatal(err)
+	}
+	log.Println("Quarantined " + b.Path + " : " + b.Reason)
+}
+
+// writeLeaderboardOutputs writes the leaderboard over all matches to leaderboard.csv.
+func writeLeaderboardOutputs(db *sql.DB) {
+	f, err := os.Create(leaderboardFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	leaderboard, err := getLeaderboard(db, "")
+	if err != nil {
+		log.Fatal(err)
+	}
+	w := csv.NewWriter(f)
+	w.Write([]string{"Rank", "Player", "Matches", "Points", "Average"})
+	for i, e := range leaderboard {
+		w.Write([]string{fmt.Sprint(i + 1), e.Player, fmt.Sprint(e.Matches), fmt.Sprint(e.Points), fmt.Sprintf("%.2f", e.Average)})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		log.Fatal(err)
+	}
+	log.Println("Leaderboard : " + leaderboardFile + " created ")
+}
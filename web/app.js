@@ -0,0 +1,194 @@
+// Scorer UI for phoenixPoints: upload, preview, import, correct and view points.
+
+const components = ["RunsScored", "Boundries", "NotOut", "Duck", "wicket", "Maidens", "NRR", "Extras", "Bowled", "catch", "runouts", "MatchWon", "OneRunOvers", "DropCatches", "KeeperBonus"];
+const perUnit = { OneRunOvers: 5, DropCatches: -3 };
+
+const $ = (id) => document.getElementById(id);
+
+async function api(method, url, body) {
+  const options = { method: method };
+  if (body instanceof FormData) {
+    options.body = body;
+  } else if (body !== undefined) {
+    options.headers = { "Content-Type": "application/json" };
+    options.body = JSON.stringify(body);
+  }
+  const response = await fetch(url, options);
+  const data = await response.json();
+  if (!response.ok) {
+    throw new Error(data.error || response.statusText);
+  }
+  return data;
+}
+
+function cell(row, value, className) {
+  const td = row.insertCell();
+  td.textContent = value;
+  if (className) {
+    td.className = className;
+  }
+  return td;
+}
+
+function numberClass(value) {
+  return value < 0 ? "neg" : "num";
+}
+
+function scorecardForm() {
+  const form = new FormData();
+  form.append("scorecard", $("scorecard").files[0]);
+  return form;
+}
+
+function renderPreview(preview) {
+  const m = preview.match;
+  $("preview-title").textContent = m.team1 + " vs " + m.team2 + " (" + m.matchDate + ", " + m.division + ")";
+  $("preview-result").textContent = m.result;
+
+  const innings = $("preview-innings");
+  innings.replaceChildren();
+  for (const card of preview.innings) {
+    const title = document.createElement("h4");
+    title.textContent = card.team + " Batting - " + card.total.runs + "/" + card.total.wickets + " (" + card.total.overs + " overs)";
+    innings.appendChild(title);
+
+    const batting = document.createElement("table");
+    const battingHead = batting.createTHead().insertRow();
+    ["Batter", "", "R", "B", "4s", "6s"].forEach((h) => { battingHead.appendChild(document.createElement("th")).textContent = h; });
+    const battingBody = batting.createTBody();
+    for (const b of card.batting || []) {
+      const row = battingBody.insertRow();
+      cell(row, b.batter);
+      cell(row, b.howOut);
+      [b.runs, b.balls, b.fours, b.sixers].forEach((v) => cell(row, v, "num"));
+    }
+    innings.appendChild(batting);
+
+    const bowling = document.createElement("table");
+    const bowlingHead = bowling.createTHead().insertRow();
+    ["Bowler", "O", "M", "R", "W", "Wd", "NB"].forEach((h) => { bowlingHead.appendChild(document.createElement("th")).textContent = h; });
+    const bowlingBody = bowling.createTBody();
+    for (const b of card.bowling || []) {
+      const row = bowlingBody.insertRow();
+      cell(row, b.bowler);
+      [b.overs, b.maidens, b.runs, b.wickets, b.wides, b.noBalls].forEach((v) => cell(row, v, "num"));
+    }
+    innings.appendChild(bowling);
+  }
+  $("preview").hidden = false;
+}
+
+function renderPoints(points) {
+  const head = $("points-head");
+  head.replaceChildren();
+  const headRow = head.insertRow();
+  ["S.No", "Player", "Role"].concat(components, ["Total Points"]).forEach((h) => { headRow.appendChild(document.createElement("th")).textContent = h; });
+
+  const rows = $("points-rows");
+  rows.replaceChildren();
+  points.forEach((p, i) => {
+    const row = rows.insertRow();
+    cell(row, i + 1, "num");
+    cell(row, p.player);
+    cell(row, p.role);
+    components.forEach((c) => cell(row, p.components[c], numberClass(p.components[c])));
+    cell(row, p.total, numberClass(p.total));
+  });
+
+  const from = $("rename-from");
+  from.replaceChildren();
+  const adjustments = $("adjustment-rows");
+  adjustments.replaceChildren();
+  for (const p of points) {
+    from.add(new Option(p.player, p.player));
+
+    const row = adjustments.insertRow();
+    cell(row, p.player);
+    for (const field of ["OneRunOvers", "DropCatches"]) {
+      const input = document.createElement("input");
+      input.type = "number";
+      input.min = "0";
+      input.value = p.components[field] / perUnit[field];
+      input.dataset.player = p.player;
+      input.dataset.field = field;
+      input.dataset.current = input.value;
+      row.insertCell().appendChild(input);
+    }
+  }
+}
+
+async function loadMatches(selected) {
+  const matches = await api("GET", "/api/matches");
+  const select = $("match");
+  select.replaceChildren();
+  for (const m of matches) {
+    select.add(new Option(m.matchid + " : " + m.team1 + " vs " + m.team2 + " (" + m.matchDate + ")", m.matchid));
+  }
+  if (selected) {
+    select.value = selected;
+  } else if (matches.length > 0) {
+    select.value = matches[matches.length - 1].matchid;
+  }
+  if (select.value) {
+    await loadPoints();
+  }
+}
+
+async function loadPoints() {
+  renderPoints(await api("GET", "/api/matches/" + $("match").value + "/points"));
+}
+
+async function run(errorId, action) {
+  $(errorId).textContent = "";
+  try {
+    await action();
+  } catch (err) {
+    $(errorId).textContent = err.message;
+  }
+}
+
+$("upload").addEventListener("submit", (event) => {
+  event.preventDefault();
+  run("upload-error", async () => renderPreview(await api("POST", "/api/scorecards/preview", scorecardForm())));
+});
+
+$("import").addEventListener("click", () => {
+  run("upload-error", async () => {
+    const imported = await api("POST", "/api/matches", scorecardForm());
+    $("preview").hidden = true;
+    $("upload").reset();
+    await loadMatches(imported.match.matchid);
+  });
+});
+
+$("match").addEventListener("change", () => run("corrections-error", loadPoints));
+
+$("rename").addEventListener("submit", (event) => {
+  event.preventDefault();
+  run("corrections-error", async () => {
+    renderPoints(await api("POST", "/api/matches/" + $("match").value + "/renames", { from: $("rename-from").value, to: $("rename-to").value }));
+    $("rename-to").value = "";
+  });
+});
+
+$("adjustments").addEventListener("submit", (event) => {
+  event.preventDefault();
+  run("corrections-error", async () => {
+    let points = null;
+    for (const input of document.querySelectorAll("#adjustment-rows input")) {
+      if (input.value === input.dataset.current) {
+        continue;
+      }
+      points = await api("POST", "/api/matches/" + $("match").value + "/adjustments", {
+        player: input.dataset.player,
+        field: input.dataset.field,
+        value: parseInt(input.value || "0", 10),
+      });
+    }
+    if (points) {
+      renderPoints(points);
+    }
+  });
+});
+
+run("corrections-error", () => loadMatches());
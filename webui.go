@@ -0,0 +1,21 @@
+package main
+
+import (
+	"embed"
+	"io/fs"
+	"log"
+	"net/http"
+)
+
+// webFiles is the scorer's web UI, built into the binary.
+//
+//go:embed web
+var webFiles embed.FS
+
+func registerWebUI(mux *http.ServeMux) {
+	webRoot, err := fs.Sub(webFiles, "web")
+	if err != nil {
+		log.Fatal(err)
+	}
+	mux.Handle("GET /", http.FileServer(http.FS(webRoot)))
+}